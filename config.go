@@ -2,57 +2,316 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Config holds data from the json config file.
 type Config struct {
 	Mappings []struct {
-		JournalTitle            string `json:"journalTitle"`
-		Prefix                  string `json:"prefix"`
-		AbbreviatedJournalTitle string `json:"abbreviatedJournalTitle"`
+		JournalTitle            string   `json:"journalTitle"`
+		Prefix                  string   `json:"prefix"`
+		AbbreviatedJournalTitle string   `json:"abbreviatedJournalTitle"`
+		CrossmarkPolicy         string   `json:"crossmarkPolicy"`
+		IssueDOIPattern         string   `json:"issueDOIPattern"`
+		IssueURLPattern         string   `json:"issueURLPattern"`
+		VolumeDOIPattern        string   `json:"volumeDOIPattern"`
+		VolumeURLPattern        string   `json:"volumeURLPattern"`
+		JournalDOI              string   `json:"journalDOI"`
+		JournalURL              string   `json:"journalURL"`
+		Coden                   string   `json:"coden"`
+		ArchiveLocations        []string `json:"archiveLocations"`
+		IssueDate               string   `json:"issueDate"`
+		SubtitleSeparators      []string `json:"subtitleSeparators"`
+		SubtitleExceptions      []string `json:"subtitleExceptions"`
+		Language                string   `json:"language"`
+		Pages                   string   `json:"pages"`
+		ArchiveLocationsIn      string   `json:"archiveLocationsIn"`
+		ArticleXML              string   `json:"articleXML"`
 	} `json:"mappings"`
 	Orcids []struct {
-		Name  string `json:"name"`
-		Orcid string `json:"orcid"`
+		Name          string `json:"name"`
+		Email         string `json:"email"`
+		Orcid         string `json:"orcid"`
+		Authenticated bool   `json:"authenticated"`
 	} `json:"orcids"`
+	Names []struct {
+		Name string `json:"name"`
+		PersonName
+	} `json:"names"`
+	Affiliations []struct {
+		Name string `json:"name"`
+		ROR  string `json:"ror"`
+	} `json:"affiliations"`
+	Organizations        []string `json:"organizations"`
+	OrganizationKeywords []string `json:"organizationKeywords"`
+	DocumentTypes        []struct {
+		DocumentType    string `json:"documentType"`
+		PublicationType string `json:"publicationType"`
+		Exclude         bool   `json:"exclude"`
+		UpdateType      string `json:"updateType"`
+	} `json:"documentTypes"`
 }
 
-// PrefixAndAbbreviation is a tuple which holds a prefix and an abbreviation for a journal title.
-type PrefixAndAbbreviation struct {
-	Prefix       string
-	Abbreviation string
+// JournalMapping holds the prefix, abbreviation, and other settings for a journal title.
+type JournalMapping struct {
+	Prefix             string
+	Abbreviation       string
+	CrossmarkPolicy    string
+	IssueDOIPattern    string
+	IssueURLPattern    string
+	VolumeDOIPattern   string
+	VolumeURLPattern   string
+	JournalDOI         string
+	JournalURL         string
+	Coden              string
+	ArchiveLocations   []string
+	IssueDateRule      string
+	SubtitleSeparators []string
+	SubtitleExceptions []string
+	Language           string
+	PagePolicy         string
+	ArchiveLocationsIn string
+	ArticleXML         string
 }
 
-// LoadConfig returns a pointer to a Config loaded with mappings from the config file.
-func LoadConfig(configFilePath string) (map[string]PrefixAndAbbreviation, map[string]string, error) {
+// DocumentTypeMapping holds how records of a DOAJ document type are deposited.
+type DocumentTypeMapping struct {
+	PublicationType string
+	Exclude         bool
+	UpdateType      string
+}
+
+// Mappings holds the lookup tables used when creating the template data.
+type Mappings struct {
+	Journals             map[string]JournalMapping
+	ORCIDs               *ORCIDIndex
+	Names                map[string]PersonName
+	Organizations        map[string]bool
+	OrganizationKeywords []string
+	DocumentTypes        map[string]DocumentTypeMapping
+	References           map[string][]Citation
+	Corrections          map[string][]Correction
+	Relations            map[string][]Relation
+	Issues               map[IssueKey]IssueMetadata
+	Roles                map[string]map[string]string
+	RORs                 map[string]string
+	RORIndex             *RORIndex
+}
+
+// archiveNames are the archives Crossref accepts in archive_locations.
+var archiveNames = []string{"CLOCKSS", "LOCKSS", "Portico", "KB", "Internet Archive", "DWT"}
+
+// archiveLocationsPlacements are the elements a journal's archive locations can be written to.
+var archiveLocationsPlacements = []string{"journal", "article"}
+
+// issueDateRules are the ways an issue's publication date can be chosen.
+var issueDateRules = []string{"earliest", "latest", "supplied"}
+
+// publicationTypes are the values Crossref accepts for the publication_type attribute.
+var publicationTypes = []string{"full_text", "abstract_only", "bibliographic_record"}
+
+// updateTypes are the values Crossref accepts for the type of a Crossmark update.
+var updateTypes = []string{
+	"addendum", "clarification", "correction", "corrigendum", "erratum", "expression_of_concern",
+	"new_edition", "new_version", "partial_retraction", "removal", "retraction", "withdrawal",
+}
+
+// LoadConfig returns a pointer to Mappings loaded from the config file. Static XML is checked against the schema version.
+func LoadConfig(configFilePath, schemaVersion string) (*Mappings, error) {
 
 	config := new(Config)
-	mapping := make(map[string]PrefixAndAbbreviation)
-	orcids := make(map[string]string)
+	mappings := &Mappings{
+		Journals:      make(map[string]JournalMapping),
+		ORCIDs:        NewORCIDIndex(),
+		Names:         make(map[string]PersonName),
+		Organizations: make(map[string]bool),
+		DocumentTypes: make(map[string]DocumentTypeMapping),
+		References:    make(map[string][]Citation),
+		Corrections:   make(map[string][]Correction),
+		Relations:     make(map[string][]Relation),
+		Issues:        make(map[IssueKey]IssueMetadata),
+		Roles:         make(map[string]map[string]string),
+		RORs:          make(map[string]string),
+	}
 
 	absoluteConfigFilePath, err := filepath.Abs(configFilePath)
 
 	configFile, err := os.Open(absoluteConfigFilePath)
 	if err != nil {
-		return mapping, orcids, err
+		return mappings, err
 	}
 	defer configFile.Close()
 
 	configDecoder := json.NewDecoder(configFile)
 	err = configDecoder.Decode(config)
 	if err != nil {
-		return mapping, orcids, err
+		return mappings, err
 	}
 
 	for _, configMapping := range config.Mappings {
-		mapping[configMapping.JournalTitle] = PrefixAndAbbreviation{configMapping.Prefix, configMapping.AbbreviatedJournalTitle}
+		if configMapping.IssueDOIPattern != "" && configMapping.IssueURLPattern == "" {
+			return mappings, fmt.Errorf("issueDOIPattern for journal title \"%v\" needs an issueURLPattern", configMapping.JournalTitle)
+		}
+		if configMapping.VolumeDOIPattern != "" && configMapping.VolumeURLPattern == "" {
+			return mappings, fmt.Errorf("volumeDOIPattern for journal title \"%v\" needs a volumeURLPattern", configMapping.JournalTitle)
+		}
+		if configMapping.CrossmarkPolicy != "" && !doiPattern.MatchString(NormalizeDOI(configMapping.CrossmarkPolicy)) {
+			return mappings, fmt.Errorf("crossmarkPolicy \"%v\" for journal title \"%v\" is not a DOI", configMapping.CrossmarkPolicy, configMapping.JournalTitle)
+		}
+		if configMapping.JournalDOI != "" {
+			if configMapping.JournalURL == "" {
+				return mappings, fmt.Errorf("journalDOI for journal title \"%v\" needs a journalURL", configMapping.JournalTitle)
+			}
+			registrantPrefix := strings.SplitN(configMapping.Prefix, "/", 2)[0] + "/"
+			if !strings.HasPrefix(NormalizeDOI(configMapping.JournalDOI), registrantPrefix) {
+				return mappings, fmt.Errorf("journalDOI \"%v\" for journal title \"%v\" does not use the prefix of its articles, %v", configMapping.JournalDOI, configMapping.JournalTitle, configMapping.Prefix)
+			}
+		}
+		for _, archive := range configMapping.ArchiveLocations {
+			if !contains(archiveNames, archive) {
+				return mappings, fmt.Errorf("invalid archive location \"%v\" for journal title \"%v\"", archive, configMapping.JournalTitle)
+			}
+		}
+		if configMapping.ArchiveLocationsIn != "" && !contains(archiveLocationsPlacements, configMapping.ArchiveLocationsIn) {
+			return mappings, fmt.Errorf("invalid archiveLocationsIn \"%v\" for journal title \"%v\"", configMapping.ArchiveLocationsIn, configMapping.JournalTitle)
+		}
+		if err := ValidateStaticXML(configMapping.ArticleXML, schemaVersion); err != nil {
+			return mappings, fmt.Errorf("articleXML for journal title \"%v\": %v", configMapping.JournalTitle, err)
+		}
+		if configMapping.IssueDate != "" && !contains(issueDateRules, configMapping.IssueDate) {
+			return mappings, fmt.Errorf("invalid issueDate \"%v\" for journal title \"%v\"", configMapping.IssueDate, configMapping.JournalTitle)
+		}
+		for _, separator := range configMapping.SubtitleSeparators {
+			if strings.TrimSpace(separator) == "" {
+				return mappings, fmt.Errorf("empty subtitle separator for journal title \"%v\"", configMapping.JournalTitle)
+			}
+		}
+		if configMapping.Pages != "" && !contains(pagePolicies, configMapping.Pages) {
+			return mappings, fmt.Errorf("invalid pages \"%v\" for journal title \"%v\"", configMapping.Pages, configMapping.JournalTitle)
+		}
+		language := ""
+		if configMapping.Language != "" {
+			language, err = LanguageCode(configMapping.Language)
+			if err != nil {
+				return mappings, fmt.Errorf("journal title \"%v\": %v", configMapping.JournalTitle, err)
+			}
+		}
+		mappings.Journals[configMapping.JournalTitle] = JournalMapping{
+			Prefix:             configMapping.Prefix,
+			Abbreviation:       configMapping.AbbreviatedJournalTitle,
+			CrossmarkPolicy:    NormalizeDOI(configMapping.CrossmarkPolicy),
+			IssueDOIPattern:    configMapping.IssueDOIPattern,
+			IssueURLPattern:    configMapping.IssueURLPattern,
+			VolumeDOIPattern:   configMapping.VolumeDOIPattern,
+			VolumeURLPattern:   configMapping.VolumeURLPattern,
+			JournalDOI:         NormalizeDOI(configMapping.JournalDOI),
+			JournalURL:         configMapping.JournalURL,
+			Coden:              configMapping.Coden,
+			ArchiveLocations:   configMapping.ArchiveLocations,
+			IssueDateRule:      configMapping.IssueDate,
+			SubtitleSeparators: configMapping.SubtitleSeparators,
+			SubtitleExceptions: configMapping.SubtitleExceptions,
+			Language:           language,
+			PagePolicy:         configMapping.Pages,
+			ArchiveLocationsIn: configMapping.ArchiveLocationsIn,
+			ArticleXML:         strings.TrimSpace(configMapping.ArticleXML),
+		}
+	}
+
+	for _, nameOverride := range config.Names {
+		if strings.TrimSpace(nameOverride.Surname) == "" {
+			return mappings, fmt.Errorf("name override for \"%v\" has no surname", nameOverride.Name)
+		}
+		mappings.Names[strings.Join(strings.Fields(nameOverride.Name), " ")] = nameOverride.PersonName
 	}
 
+	// Names are loaded first, so ORCIDs can be keyed by the overridden surname.
 	for _, orcidpair := range config.Orcids {
-		orcids[orcidpair.Name] = orcidpair.Orcid
+		if strings.TrimSpace(orcidpair.Name) == "" && strings.TrimSpace(orcidpair.Email) == "" {
+			return mappings, fmt.Errorf("orcid \"%v\" has no name or email", orcidpair.Orcid)
+		}
+		orcid, err := NormalizeORCID(orcidpair.Orcid)
+		if err != nil {
+			return mappings, err
+		}
+		mappings.ORCIDs.Add(orcidpair.Name, orcidpair.Email, orcid, orcidpair.Authenticated, mappings.Names)
+	}
+
+	for _, affiliation := range config.Affiliations {
+		mappings.RORs[normalizeName(affiliation.Name)] = NormalizeROR(affiliation.ROR)
+	}
+
+	for _, organization := range config.Organizations {
+		mappings.Organizations[normalizeName(organization)] = true
+	}
+
+	for _, keyword := range config.OrganizationKeywords {
+		mappings.OrganizationKeywords = append(mappings.OrganizationKeywords, normalizeName(keyword))
 	}
 
-	return mapping, orcids, nil
+	for _, documentType := range config.DocumentTypes {
+		documentTypeMapping := DocumentTypeMapping{
+			PublicationType: documentType.PublicationType,
+			Exclude:         documentType.Exclude,
+			UpdateType:      documentType.UpdateType,
+		}
+		if documentTypeMapping.PublicationType == "" {
+			documentTypeMapping.PublicationType = "full_text"
+		}
+		if !contains(publicationTypes, documentTypeMapping.PublicationType) {
+			return mappings, fmt.Errorf("invalid publication type \"%v\" for document type \"%v\"", documentType.PublicationType, documentType.DocumentType)
+		}
+		if documentTypeMapping.UpdateType != "" && !contains(updateTypes, documentTypeMapping.UpdateType) {
+			return mappings, fmt.Errorf("invalid update type \"%v\" for document type \"%v\"", documentType.UpdateType, documentType.DocumentType)
+		}
+		mappings.DocumentTypes[normalizeDocumentType(documentType.DocumentType)] = documentTypeMapping
+	}
+
+	return mappings, nil
+}
+
+// DocumentType returns the mapping for a record's document type. Unmapped document types are deposited as full text.
+func (m *Mappings) DocumentType(record *DOAJRecord) DocumentTypeMapping {
+
+	if record.DOAJDocumentType != nil {
+		if documentTypeMapping, ok := m.DocumentTypes[normalizeDocumentType(record.DOAJDocumentType.Text)]; ok {
+			return documentTypeMapping
+		}
+	}
+
+	return DocumentTypeMapping{PublicationType: "full_text"}
+}
+
+// IsOrganization reports whether a contributor name is an organization, either because it is listed in
+// the config file, or because it contains one of the organization keywords as a whole word.
+func (m *Mappings) IsOrganization(name string) bool {
+
+	normalized := normalizeName(name)
+	if m.Organizations[normalized] {
+		return true
+	}
+
+	for _, keyword := range m.OrganizationKeywords {
+		if keyword != "" && strings.Contains(" "+normalized+" ", " "+keyword+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeDocumentType(documentType string) string {
+	return strings.ToLower(strings.TrimSpace(documentType))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Correction is a Crossmark update made by a correcting article to a previously published DOI.
+type Correction struct {
+	DOI  string
+	Type string
+}
+
+// LoadCorrections loads corrections from a csv file, keyed by the URL of the correcting article.
+// The file has a header row, followed by rows of the correcting article's URL, the updated DOI, and the update type.
+// The update type may be left empty for articles whose document type has an update type.
+func LoadCorrections(correctionsFilePath string) (map[string][]Correction, error) {
+
+	corrections := make(map[string][]Correction)
+
+	rows, err := readCSVRows(correctionsFilePath, 3)
+	if err != nil {
+		return corrections, err
+	}
+
+	for _, row := range rows {
+		uri := strings.TrimSpace(row[0])
+		correction := Correction{
+			DOI:  NormalizeDOI(row[1]),
+			Type: strings.ToLower(strings.TrimSpace(row[2])),
+		}
+
+		if correction.DOI == "" {
+			return corrections, fmt.Errorf("correction for \"%v\" has no DOI", uri)
+		}
+		if correction.Type != "" && !contains(updateTypes, correction.Type) {
+			return corrections, fmt.Errorf("correction for \"%v\" has invalid update type \"%v\"", uri, row[2])
+		}
+
+		corrections[uri] = append(corrections[uri], correction)
+	}
+
+	return corrections, nil
+}
@@ -12,10 +12,12 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // TemplateData contains the data to use when creating the template
 type TemplateData struct {
+	SchemaVersion string
 	HeadData
 	BodyData
 }
@@ -40,9 +42,20 @@ type Journal struct {
 	FullTitle        string
 	AbbrevTitle      string
 	ISSNs            []ISSN
+	Coden            string
+	ArchiveLocations []string
+	JournalDOI       string
+	JournalURI       string
 	PublicationDates []PublicationDate
+	Contributors     []Contributor
+	Title            string
 	Volume           string
+	VolumeDOI        string
+	VolumeURI        string
 	Issue            string
+	SpecialNumbering string
+	IssueDOI         string
+	IssueURI         string
 	Articles         []Article
 }
 
@@ -62,31 +75,80 @@ type PublicationDate struct {
 
 // Article contains data about each article.
 type Article struct {
-	Title            string
-	Contributors     []Contributor
-	PublicationDates []PublicationDate
-	DOI              string
-	URI              string
-	FirstPage        string
-	LastPage         string
+	PublicationType       string
+	Language              string
+	Title                 string
+	Subtitle              string
+	OriginalLanguageTitle *ArticleTitle
+	Abstract              string
+	Contributors          []Contributor
+	PublicationDates      []PublicationDate
+	DOI                   string
+	URI                   string
+	FirstPage             string
+	LastPage              string
+	OtherPages            string
+	ItemNumber            string
+	Crossmark             *Crossmark
+	StaticXML             string
+	ArchiveLocations      []string
+	Relations             []Relation
+	FullTextLinks         []FullTextLink
+	Citations             []Citation
+}
+
+// ArticleTitle is an article title and subtitle in a language.
+type ArticleTitle struct {
+	Language string
+	Title    string
+	Subtitle string
+}
+
+// Crossmark contains the Crossmark policy and updates for an article.
+type Crossmark struct {
+	Policy  string
+	Updates []Update
+}
+
+// Update is a Crossmark update relation, pointing at the DOI which an article updates.
+type Update struct {
+	Type string
+	Date string
+	DOI  string
+}
+
+// FullTextLink is a link to a full text version of an article, used for Similarity Check and text mining.
+type FullTextLink struct {
+	URI      string
+	MimeType string
 }
 
-// Contributor contains data about each author.
+// Contributor contains data about each author. Organization is set instead of the name parts for organizational authors.
+// ORCIDAuthenticated is set when the ORCID was collected through an authenticated workflow.
 type Contributor struct {
-	GivenName   string
-	Surname     string
-	Affiliation string
-	Sequence    string
-	Role        string
-	ORCID       string
+	Organization       string
+	GivenName          string
+	Surname            string
+	Suffix             string
+	Affiliations       []Affiliation
+	Sequence           string
+	Role               string
+	ORCID              string
+	ORCIDAuthenticated bool
+}
+
+// Affiliation contains the name and ROR ID of a contributor's institution.
+type Affiliation struct {
+	Name string
+	ROR  string
 }
 
 // CreateTemplateData returns a pointer to a 'fully hydrated' TemplateData struct.
-func CreateTemplateData(depositorName, depositorEmail, registrant string,
-	mapping map[string]PrefixAndAbbreviation, orcids map[string]string,
-	records *DOAJRecords) *TemplateData {
+func CreateTemplateData(depositorName, depositorEmail, registrant, schemaVersion string,
+	mappings *Mappings, records *DOAJRecords) *TemplateData {
 
 	templateData := new(TemplateData)
+	templateData.SchemaVersion = schemaVersion
 
 	templateData.HeadData = HeadData{
 		DOIBatch:       time.Now().UTC().Unix(),
@@ -97,15 +159,35 @@ func CreateTemplateData(depositorName, depositorEmail, registrant string,
 	}
 
 	for _, record := range records.DOAJRecords {
-		journal := GetOrCreateJournal(&templateData.BodyData, mapping, record)
-		journal.AddArticle(mapping, orcids, record)
+		journal := GetOrCreateJournal(&templateData.BodyData, mappings, record)
+		journal.AddArticle(mappings, record)
+	}
+
+	for _, journal := range templateData.Journals {
+		journal.SetPublicationDates(mappings)
 	}
 
 	return templateData
 }
 
+// FilterRecords returns the records whose document type is not excluded.
+func FilterRecords(mappings *Mappings, records *DOAJRecords) *DOAJRecords {
+
+	filtered := new(DOAJRecords)
+
+	for _, record := range records.DOAJRecords {
+		if mappings.DocumentType(record).Exclude {
+			log.Printf("Excluding %v with URL %v\n", record.DOAJDocumentType.Text, record.LandingPageURL())
+			continue
+		}
+		filtered.DOAJRecords = append(filtered.DOAJRecords, record)
+	}
+
+	return filtered
+}
+
 // GetOrCreateJournal returns a pointer to an existing or newly added journal.
-func GetOrCreateJournal(bodyData *BodyData, mapping map[string]PrefixAndAbbreviation, record *DOAJRecord) *Journal {
+func GetOrCreateJournal(bodyData *BodyData, mappings *Mappings, record *DOAJRecord) *Journal {
 
 	for i := range bodyData.Journals {
 		journal := bodyData.Journals[i]
@@ -118,14 +200,38 @@ func GetOrCreateJournal(bodyData *BodyData, mapping map[string]PrefixAndAbbrevia
 	}
 
 	journal := &Journal{
-		LanguageCode:     ISO6392toISO6391(record.DOAJLanguage.Text),
-		FullTitle:        record.DOAJJournalTitle.Text,
-		AbbrevTitle:      mapping[record.DOAJJournalTitle.Text].Abbreviation,
-		ISSNs:            CreateISSNs(record),
-		PublicationDates: CreatePublicationDates(record),
-		Volume:           record.DOAJVolume.Text,
-		Issue:            record.DOAJIssue.Text,
-		Articles:         []Article{},
+		LanguageCode: record.LanguageCode(),
+		FullTitle:    record.DOAJJournalTitle.Text,
+		AbbrevTitle:  mappings.Journals[record.DOAJJournalTitle.Text].Abbreviation,
+		ISSNs:        CreateISSNs(record),
+		Volume:       record.DOAJVolume.Text,
+		Issue:        record.DOAJIssue.Text,
+		Articles:     []Article{},
+	}
+
+	journalMapping := mappings.Journals[journal.FullTitle]
+	if journalMapping.Language != "" {
+		journal.LanguageCode = journalMapping.Language
+	}
+	journal.Coden = escapeXML(journalMapping.Coden)
+	if journalMapping.ArchiveLocationsIn != "article" {
+		journal.ArchiveLocations = journalMapping.ArchiveLocations
+	}
+	journal.JournalDOI = escapeXML(journalMapping.JournalDOI)
+	journal.JournalURI = escapeXML(journalMapping.JournalURL)
+
+	issue := mappings.Issues[IssueKey{journal.FullTitle, journal.Volume, journal.Issue}]
+	journal.Title = escapeXML(strings.TrimSpace(issue.Title))
+	journal.SpecialNumbering = escapeXML(strings.TrimSpace(issue.SpecialNumbering))
+	journal.Contributors = CreateEditors(issue.Editors, mappings)
+
+	if journalMapping.IssueDOIPattern != "" {
+		journal.IssueDOI = journal.ExpandDOIPattern(journalMapping.IssueDOIPattern, journalMapping.Prefix)
+		journal.IssueURI = escapeXML(journal.ExpandPattern(journalMapping.IssueURLPattern, journalMapping.Prefix))
+	}
+	if journalMapping.VolumeDOIPattern != "" {
+		journal.VolumeDOI = journal.ExpandDOIPattern(journalMapping.VolumeDOIPattern, journalMapping.Prefix)
+		journal.VolumeURI = escapeXML(journal.ExpandPattern(journalMapping.VolumeURLPattern, journalMapping.Prefix))
 	}
 
 	bodyData.Journals = append(bodyData.Journals, journal)
@@ -133,6 +239,39 @@ func GetOrCreateJournal(bodyData *BodyData, mapping map[string]PrefixAndAbbrevia
 	return journal
 }
 
+// Languages returns the distinct languages of the issue's articles, in the order they first appear.
+func (j *Journal) Languages() []string {
+
+	languages := []string{}
+	for _, article := range j.Articles {
+		if article.Language != "" && !contains(languages, article.Language) {
+			languages = append(languages, article.Language)
+		}
+	}
+
+	return languages
+}
+
+// ExpandPattern replaces the {prefix}, {volume} and {issue} placeholders in an issue or volume DOI or URL pattern.
+func (j *Journal) ExpandPattern(pattern, prefix string) string {
+	return strings.NewReplacer(
+		"{prefix}", prefix,
+		"{volume}", j.Volume,
+		"{issue}", j.Issue,
+	).Replace(pattern)
+}
+
+// ExpandDOIPattern expands an issue or volume DOI pattern, stopping the conversion if it isn't a valid DOI.
+func (j *Journal) ExpandDOIPattern(pattern, prefix string) string {
+
+	doi := j.ExpandPattern(pattern, prefix)
+	if !doiPattern.MatchString(doi) {
+		log.Fatalf("DOI pattern \"%v\" for volume \"%v\" issue \"%v\" of %v gives \"%v\", which is not a valid DOI.\n", pattern, j.Volume, j.Issue, j.FullTitle, doi)
+	}
+
+	return escapeXML(doi)
+}
+
 // CreateISSNs returns a slice of ISSNs.
 func CreateISSNs(record *DOAJRecord) []ISSN {
 	return []ISSN{
@@ -142,53 +281,372 @@ func CreateISSNs(record *DOAJRecord) []ISSN {
 
 // CreatePublicationDates returns a slice of Publication Dates. The dates are parsed to ensure they're OK.
 func CreatePublicationDates(record *DOAJRecord) []PublicationDate {
+	return []PublicationDate{
+		NewPublicationDate(record.DOAJPublicationDate.Text, "online"),
+	}
+}
+
+// NewPublicationDate parses a full or partial date into a PublicationDate of the given media type.
+// The month and day are left empty if they are not part of the date.
+func NewPublicationDate(date, mediaType string) PublicationDate {
 
-	t, err := time.Parse("2006-01-02", record.DOAJPublicationDate.Text)
+	t, layout, err := ParseDate(date)
 	if err != nil {
-		log.Fatalln("Unable to process date", record.DOAJPublicationDate.Text, err)
+		log.Fatalln("Unable to process date", date, err)
 	}
 
-	return []PublicationDate{
-		PublicationDate{
-			strconv.Itoa(t.Year()),
-			fmt.Sprintf("%02d", int(t.Month())),
-			fmt.Sprintf("%02d", t.Day()),
-			"online",
-		},
+	publicationDate := PublicationDate{Year: strconv.Itoa(t.Year()), Type: mediaType}
+	if layout != "2006" {
+		publicationDate.Month = fmt.Sprintf("%02d", int(t.Month()))
+	}
+	if layout == "2006-01-02" {
+		publicationDate.Day = fmt.Sprintf("%02d", t.Day())
+	}
+
+	return publicationDate
+}
+
+// Before reports whether the publication date is before another. A partial date is before the full dates within it.
+func (d PublicationDate) Before(other PublicationDate) bool {
+	return d.Year+d.Month+d.Day < other.Year+other.Month+other.Day
+}
+
+// SetPublicationDates sets the issue's publication dates using the journal's issue date rule.
+// The online issue date is the earliest or latest article date, or the date supplied in the issue metadata.
+// A print date supplied in the issue metadata is added to the issue and to each of its articles.
+func (j *Journal) SetPublicationDates(mappings *Mappings) {
+
+	issue := mappings.Issues[IssueKey{j.FullTitle, j.Volume, j.Issue}]
+
+	switch mappings.Journals[j.FullTitle].IssueDateRule {
+	case "supplied":
+		if strings.TrimSpace(issue.PublicationDate) == "" {
+			log.Fatalf("No publication date supplied for journal title \"%v\" volume %v issue %v.\n", j.FullTitle, j.Volume, j.Issue)
+		}
+		j.PublicationDates = []PublicationDate{NewPublicationDate(issue.PublicationDate, "online")}
+	case "latest":
+		j.PublicationDates = []PublicationDate{j.Articles[0].PublicationDates[0]}
+		for _, article := range j.Articles[1:] {
+			if j.PublicationDates[0].Before(article.PublicationDates[0]) {
+				j.PublicationDates[0] = article.PublicationDates[0]
+			}
+		}
+	default:
+		j.PublicationDates = []PublicationDate{j.Articles[0].PublicationDates[0]}
+		for _, article := range j.Articles[1:] {
+			if article.PublicationDates[0].Before(j.PublicationDates[0]) {
+				j.PublicationDates[0] = article.PublicationDates[0]
+			}
+		}
+	}
+
+	if strings.TrimSpace(issue.PrintDate) != "" {
+		printDate := NewPublicationDate(issue.PrintDate, "print")
+		j.PublicationDates = append([]PublicationDate{printDate}, j.PublicationDates...)
+		for i := range j.Articles {
+			j.Articles[i].PublicationDates = append([]PublicationDate{printDate}, j.Articles[i].PublicationDates...)
+		}
 	}
 }
 
 // AddArticle adds an article's metadata from the record to a journal.
-func (j *Journal) AddArticle(mapping map[string]PrefixAndAbbreviation, orcids map[string]string, record *DOAJRecord) {
+func (j *Journal) AddArticle(mappings *Mappings, record *DOAJRecord) {
 
-	fulltextURL, err := url.Parse(record.DOAJFullTextURL.Text)
+	landingPageURL := record.LandingPageURL()
+
+	fulltextURL, err := url.Parse(landingPageURL)
 	if err != nil {
-		log.Fatalln("Unable to parse full text url", record.DOAJFullTextURL.Text, err)
+		log.Fatalln("Unable to parse full text url", landingPageURL, err)
 	}
 
-	prefix := mapping[j.FullTitle].Prefix
-	if prefix == "" {
-		log.Fatalf("Unable to find prefix for journal title \"%v\", maybe missing data at for article with url \"%v\".\n", j.FullTitle, record.DOAJFullTextURL.Text)
+	doi := CreateDOI(mappings, record)
+
+	references, ok := mappings.References[landingPageURL]
+	if !ok {
+		references = mappings.References[path.Base(fulltextURL.Path)]
+	}
+
+	documentType := mappings.DocumentType(record)
+	journalMapping := mappings.Journals[j.FullTitle]
+
+	crossmark := CreateCrossmark(journalMapping.CrossmarkPolicy,
+		CreateUpdates(documentType, mappings.Corrections[landingPageURL], record), record)
+
+	title, originalLanguageTitle := CreateTitles(record, j.LanguageCode, journalMapping)
+
+	abstract := ""
+	if record.DOAJAbstract != nil {
+		abstract = JATSAbstract(record.DOAJAbstract.Text)
 	}
 
-	doi := prefix + path.Base(fulltextURL.Path)
+	archiveLocations := []string{}
+	if journalMapping.ArchiveLocationsIn == "article" {
+		archiveLocations = journalMapping.ArchiveLocations
+	}
 
-	firstPage := record.DOAJStartPage.Text
-	if record.DOAJStartPage.Text == "" {
-		firstPage = "1"
+	pagePolicy := journalMapping.PagePolicy
+	pages := CreatePages(record)
+	if pages.FirstPage == "" && (pagePolicy == "" || pagePolicy == "default") {
+		pages.FirstPage = "1"
 	}
 
 	j.Articles = append(j.Articles, Article{
-		Title:            escapeXML(record.DOAJTitle.Text),
-		URI:              record.DOAJFullTextURL.Text,
-		FirstPage:        firstPage,
-		LastPage:         record.DOAJEndPage.Text,
-		DOI:              doi,
-		PublicationDates: j.PublicationDates,
-		Contributors:     CreateContributors(record, orcids),
+		PublicationType:       documentType.PublicationType,
+		Language:              record.LanguageCode(),
+		Title:                 title.Title,
+		Subtitle:              title.Subtitle,
+		OriginalLanguageTitle: originalLanguageTitle,
+		Abstract:              abstract,
+		URI:                   landingPageURL,
+		FirstPage:             escapeXML(pages.FirstPage),
+		LastPage:              escapeXML(pages.LastPage),
+		OtherPages:            escapeXML(pages.OtherPages),
+		ItemNumber:            escapeXML(ItemNumber(pagePolicy, record)),
+		DOI:                   doi,
+		PublicationDates:      CreatePublicationDates(record),
+		Contributors:          CreateContributors(record, mappings),
+		Crossmark:             crossmark,
+		StaticXML:             journalMapping.ArticleXML,
+		ArchiveLocations:      archiveLocations,
+		Relations:             mappings.Relations[landingPageURL],
+		FullTextLinks:         CreateFullTextLinks(record, landingPageURL),
+		Citations:             CreateCitations(references),
 	})
 }
 
+// CreateDOI returns a record's DOI, made from its journal's prefix and the last element of its landing page URL's path.
+func CreateDOI(mappings *Mappings, record *DOAJRecord) string {
+
+	landingPageURL := record.LandingPageURL()
+
+	fulltextURL, err := url.Parse(landingPageURL)
+	if err != nil {
+		log.Fatalln("Unable to parse full text url", landingPageURL, err)
+	}
+
+	prefix := mappings.Journals[record.DOAJJournalTitle.Text].Prefix
+	if prefix == "" {
+		log.Fatalf("Unable to find prefix for journal title \"%v\", maybe missing data at for article with url \"%v\".\n", record.DOAJJournalTitle.Text, landingPageURL)
+	}
+
+	return prefix + path.Base(fulltextURL.Path)
+}
+
+// CreateCrossmark returns Crossmark metadata for an article, or nil if its journal has no Crossmark policy.
+func CreateCrossmark(policy string, updates []Update, record *DOAJRecord) *Crossmark {
+
+	if policy == "" {
+		if len(updates) > 0 {
+			log.Fatalf("Unable to find a Crossmark policy for journal title \"%v\", needed for the %v with url \"%v\".\n", record.DOAJJournalTitle.Text, updates[0].Type, record.LandingPageURL())
+		}
+		return nil
+	}
+
+	return &Crossmark{
+		Policy:  escapeXML(policy),
+		Updates: updates,
+	}
+}
+
+// CreateUpdates returns a slice of the Crossmark updates made by a record, from the corrections file rows for its URL.
+func CreateUpdates(documentType DocumentTypeMapping, corrections []Correction, record *DOAJRecord) []Update {
+
+	updates := []Update{}
+	if documentType.UpdateType == "" && len(corrections) == 0 {
+		return updates
+	}
+	if len(corrections) == 0 {
+		log.Fatalf("Unable to find the updated DOI for the %v with url \"%v\". Add it to the corrections file.\n", documentType.UpdateType, record.LandingPageURL())
+	}
+
+	// Crossmark update dates must be full dates, so partial dates are given the first month or day.
+	t, _, err := ParseDate(record.DOAJPublicationDate.Text)
+	if err != nil {
+		log.Fatalln("Unable to process date", record.DOAJPublicationDate.Text, err)
+	}
+	date := t.Format("2006-01-02")
+
+	for _, correction := range corrections {
+		updateType := correction.Type
+		if updateType == "" {
+			updateType = documentType.UpdateType
+		}
+		if updateType == "" {
+			log.Fatalf("Unable to find the update type of the correction to %v with url \"%v\".\n", correction.DOI, record.LandingPageURL())
+		}
+		updates = append(updates, Update{updateType, date, escapeXML(correction.DOI)})
+	}
+
+	return updates
+}
+
+// NormalizeDOI strips resolver and "doi:" prefixes from a DOI.
+func NormalizeDOI(doi string) string {
+	doi = strings.TrimSpace(doi)
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		if strings.HasPrefix(strings.ToLower(doi), prefix) {
+			return doi[len(prefix):]
+		}
+	}
+	return doi
+}
+
+// CreateFullTextLinks returns a slice of links to the full text URLs other than the landing page.
+func CreateFullTextLinks(record *DOAJRecord, landingPageURL string) []FullTextLink {
+
+	links := []FullTextLink{}
+
+	for _, fullTextURL := range record.DOAJFullTextURL {
+		if fullTextURL.Text == landingPageURL {
+			continue
+		}
+		links = append(links, FullTextLink{
+			URI:      escapeXML(strings.TrimSpace(fullTextURL.Text)),
+			MimeType: FormatToMimeType(fullTextURL.AttrFormat),
+		})
+	}
+
+	return links
+}
+
+// CreateTitles returns the primary title of a record and its original language title, or nil if it has none.
+func CreateTitles(record *DOAJRecord, journalLanguageCode string, journalMapping JournalMapping) (ArticleTitle, *ArticleTitle) {
+
+	titles := []ArticleTitle{}
+	for _, title := range record.DOAJTitle {
+		if strings.TrimSpace(title.Text) == "" {
+			continue
+		}
+		language := record.LanguageCode()
+		if title.AttrLanguage != "" {
+			language, _ = LanguageCode(title.AttrLanguage)
+		}
+		mainTitle, subtitle := SplitSubtitle(title.Text, journalMapping.SubtitleSeparators, journalMapping.SubtitleExceptions)
+		titles = append(titles, ArticleTitle{language, FaceMarkup(mainTitle), FaceMarkup(subtitle)})
+	}
+
+	if len(titles) == 0 {
+		return ArticleTitle{}, nil
+	}
+
+	primary := 0
+	for i, title := range titles {
+		if title.Language == journalLanguageCode {
+			primary = i
+			break
+		}
+	}
+
+	others := append(append([]ArticleTitle{}, titles[:primary]...), titles[primary+1:]...)
+	if len(others) == 0 {
+		return titles[primary], nil
+	}
+	for _, title := range others[1:] {
+		log.Printf("Leaving out the %v title \"%v\" of the article with url \"%v\", which already has an original language title.\n", title.Language, title.Title, record.LandingPageURL())
+	}
+
+	return titles[primary], &others[0]
+}
+
+// SplitSubtitle splits a title into a title and subtitle at the first separator outside of its exceptions and markup.
+func SplitSubtitle(title string, separators, exceptions []string) (string, string) {
+
+	title = strings.Join(strings.Fields(title), " ")
+
+	start := 0
+	for _, exception := range exceptions {
+		exception = strings.Join(strings.Fields(exception), " ")
+		if exception != "" && len(exception) > start && strings.HasPrefix(strings.ToLower(title), strings.ToLower(exception)) {
+			start = len(exception)
+		}
+	}
+
+	topLevel := topLevelText(title)
+
+	split, end := -1, -1
+	for _, separator := range separators {
+		for offset := start; offset < len(title); {
+			i := strings.Index(title[offset:], separator)
+			if i < 0 {
+				break
+			}
+			i += offset
+			offset = i + len(separator)
+			if isASCIIPunctuation(separator) && offset < len(title) && title[offset] != ' ' {
+				continue
+			}
+			if !allTopLevel(topLevel[i:offset]) {
+				continue
+			}
+			if split < 0 || i < split {
+				split, end = i, offset
+			}
+			break
+		}
+	}
+
+	if split < 0 {
+		return title, ""
+	}
+
+	mainTitle := strings.TrimSpace(title[:split])
+	subtitle := strings.TrimSpace(title[end:])
+	if mainTitle == "" || subtitle == "" {
+		return title, ""
+	}
+
+	return mainTitle, subtitle
+}
+
+func allTopLevel(topLevel []bool) bool {
+	for _, b := range topLevel {
+		if !b {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIPunctuation(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsPunct(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// CrawlerLinks returns the PDF full text links, which Similarity Check crawls.
+func (a Article) CrawlerLinks() []FullTextLink {
+
+	links := []FullTextLink{}
+	for _, link := range a.FullTextLinks {
+		if link.MimeType == "application/pdf" {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// FormatToMimeType maps a DOAJ full text format to a mime type. Unknown formats return an empty string.
+func FormatToMimeType(format string) string {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "pdf":
+		return "application/pdf"
+	case "html":
+		return "text/html"
+	case "xml":
+		return "application/xml"
+	case "epub":
+		return "application/epub+zip"
+	case "txt":
+		return "text/plain"
+	default:
+		return ""
+	}
+}
+
 func escapeXML(s string) string {
 	escaped := new(bytes.Buffer)
 	err := xml.EscapeText(escaped, []byte(s))
@@ -199,33 +657,83 @@ func escapeXML(s string) string {
 }
 
 // CreateContributors creates a slice of contributors. Mononymous people only set the surname.
-func CreateContributors(record *DOAJRecord, orcids map[string]string) []Contributor {
+func CreateContributors(record *DOAJRecord, mappings *Mappings) []Contributor {
 
-	idToAffiliation := make(map[int8]string)
+	idToAffiliation := make(map[int64]string)
 	contributors := []Contributor{}
 
-	for _, affiliation := range record.DOAJAffiliationsList.DOAJAffiliationName {
-		idToAffiliation[affiliation.AttrAffiliationID] = strings.TrimSpace(affiliation.Text)
+	if record.DOAJAffiliationsList != nil {
+		for _, affiliation := range record.DOAJAffiliationsList.DOAJAffiliationName {
+			idToAffiliation[affiliation.AttrAffiliationID] = strings.TrimSpace(affiliation.Text)
+		}
 	}
 
 	if len(record.DOAJAuthors.DOAJAuthor) == 0 {
-		log.Fatalln("No authors:", record.DOAJFullTextURL.Text)
+		log.Fatalln("No authors:", record.LandingPageURL())
 	}
 
-	for i, contributor := range record.DOAJAuthors.DOAJAuthor {
+	roles := mappings.Roles[record.LandingPageURL()]
+	seenRoles := make(map[string]bool)
+
+	for _, contributor := range record.DOAJAuthors.DOAJAuthor {
+
+		name, inputORCID := SplitORCID(contributor.DOAJName.Text)
+		if contributor.DOAJORCID != nil && strings.TrimSpace(contributor.DOAJORCID.Text) != "" {
+			inputORCID = contributor.DOAJORCID.Text
+		}
+		name, role := SplitRole(name)
+		if override, ok := roles[normalizeRoleName(name)]; ok {
+			role = override
+		}
+
+		c := Contributor{Role: role}
+		c.SetName(name, mappings)
 
-		c := Contributor{Role: "author"}
+		for _, affiliationID := range contributor.DOAJAffiliationID {
+			affiliation := idToAffiliation[affiliationID.Text]
+			if affiliation == "" {
+				continue
+			}
+			c.Affiliations = append(c.Affiliations, Affiliation{escapeXML(affiliation), mappings.ROR(affiliation)})
+		}
 
-		if len(strings.SplitN(contributor.DOAJName.Text, " ", 2)) == 1 {
-			c.Surname = contributor.DOAJName.Text
+		// Sequence is numbered separately for each role.
+		if !seenRoles[role] {
+			c.Sequence = "first"
+			seenRoles[role] = true
 		} else {
-			c.GivenName = strings.SplitN(contributor.DOAJName.Text, " ", 2)[0]
-			c.Surname = strings.SplitN(contributor.DOAJName.Text, " ", 2)[1]
+			c.Sequence = "additional"
 		}
 
-		if contributor.DOAJAffiliationID != nil {
-			c.Affiliation = escapeXML(idToAffiliation[contributor.DOAJAffiliationID.Text])
+		email := ""
+		if contributor.DOAJEmail != nil {
+			email = contributor.DOAJEmail.Text
+		}
+		orcid, authenticated, err := mappings.ORCID(name, email, inputORCID)
+		if err != nil {
+			log.Printf("Unable to choose an ORCID for the article with url \"%v\": %v\n", record.LandingPageURL(), err)
 		}
+		c.ORCID = orcid
+		c.ORCIDAuthenticated = authenticated
+
+		contributors = append(contributors, c)
+	}
+
+	return contributors
+
+}
+
+// CreateEditors creates a slice of editor contributors from a list of names.
+func CreateEditors(names []string, mappings *Mappings) []Contributor {
+
+	contributors := []Contributor{}
+
+	for i, name := range names {
+
+		name, inputORCID := SplitORCID(name)
+
+		c := Contributor{Role: "editor"}
+		c.SetName(name, mappings)
 
 		if i == 0 {
 			c.Sequence = "first"
@@ -233,428 +741,29 @@ func CreateContributors(record *DOAJRecord, orcids map[string]string) []Contribu
 			c.Sequence = "additional"
 		}
 
-		orcid := orcids[contributor.DOAJName.Text]
-		if orcid != "" {
-			c.ORCID = "https://orcid.org/" + orcid
+		orcid, authenticated, err := mappings.ORCID(name, "", inputORCID)
+		if err != nil {
+			log.Printf("Unable to choose an ORCID for editor \"%v\": %v\n", strings.TrimSpace(name), err)
 		}
+		c.ORCID = orcid
+		c.ORCIDAuthenticated = authenticated
 
 		contributors = append(contributors, c)
 	}
 
 	return contributors
-
 }
 
-// ISO6392toISO6391 flips the language encoding used in DOAJ to the one used in Crossref.
-func ISO6392toISO6391(code string) string {
-	switch code {
-	case "aar":
-		return "aa"
-	case "afr":
-		return "af"
-	case "aka":
-		return "ak"
-	case "alb":
-		return "sq"
-	case "amh":
-		return "am"
-	case "ara":
-		return "ar"
-	case "arg":
-		return "an"
-	case "arm":
-		return "hy"
-	case "asm":
-		return "as"
-	case "ava":
-		return "av"
-	case "ave":
-		return "ae"
-	case "aym":
-		return "ay"
-	case "aze":
-		return "az"
-	case "bak":
-		return "ba"
-	case "bam":
-		return "bm"
-	case "baq":
-		return "eu"
-	case "bel":
-		return "be"
-	case "ben":
-		return "bn"
-	case "bih":
-		return "bh"
-	case "bis":
-		return "bi"
-	case "bod":
-		return "bo"
-	case "bos":
-		return "bs"
-	case "bre":
-		return "br"
-	case "bul":
-		return "bg"
-	case "bur":
-		return "my"
-	case "cat":
-		return "ca"
-	case "ces":
-		return "cs"
-	case "cha":
-		return "ch"
-	case "che":
-		return "ce"
-	case "chi":
-		return "zh"
-	case "chu":
-		return "cu"
-	case "chv":
-		return "cv"
-	case "cor":
-		return "kw"
-	case "cos":
-		return "co"
-	case "cre":
-		return "cr"
-	case "cym":
-		return "cy"
-	case "cze":
-		return "cs"
-	case "dan":
-		return "da"
-	case "deu":
-		return "de"
-	case "div":
-		return "dv"
-	case "dut":
-		return "nl"
-	case "dzo":
-		return "dz"
-	case "ell":
-		return "el"
-	case "eng":
-		return "en"
-	case "epo":
-		return "eo"
-	case "est":
-		return "et"
-	case "eus":
-		return "eu"
-	case "ewe":
-		return "ee"
-	case "fao":
-		return "fo"
-	case "fas":
-		return "fa"
-	case "fij":
-		return "fj"
-	case "fin":
-		return "fi"
-	case "fra":
-		return "fr"
-	case "fre":
-		return "fr"
-	case "fry":
-		return "fy"
-	case "ful":
-		return "ff"
-	case "geo":
-		return "ka"
-	case "ger":
-		return "de"
-	case "gla":
-		return "gd"
-	case "gle":
-		return "ga"
-	case "glg":
-		return "gl"
-	case "glv":
-		return "gv"
-	case "gre":
-		return "el"
-	case "grn":
-		return "gn"
-	case "guj":
-		return "gu"
-	case "hat":
-		return "ht"
-	case "hau":
-		return "ha"
-	case "heb":
-		return "he"
-	case "her":
-		return "hz"
-	case "hin":
-		return "hi"
-	case "hmo":
-		return "ho"
-	case "hrv":
-		return "hr"
-	case "hun":
-		return "hu"
-	case "hye":
-		return "hy"
-	case "ibo":
-		return "ig"
-	case "ice":
-		return "is"
-	case "ido":
-		return "io"
-	case "iii":
-		return "ii"
-	case "iku":
-		return "iu"
-	case "ile":
-		return "ie"
-	case "ina":
-		return "ia"
-	case "ind":
-		return "id"
-	case "ipk":
-		return "ik"
-	case "isl":
-		return "is"
-	case "ita":
-		return "it"
-	case "jav":
-		return "jv"
-	case "jpn":
-		return "ja"
-	case "kal":
-		return "kl"
-	case "kan":
-		return "kn"
-	case "kas":
-		return "ks"
-	case "kat":
-		return "ka"
-	case "kau":
-		return "kr"
-	case "kaz":
-		return "kk"
-	case "khm":
-		return "km"
-	case "kik":
-		return "ki"
-	case "kin":
-		return "rw"
-	case "kir":
-		return "ky"
-	case "kom":
-		return "kv"
-	case "kon":
-		return "kg"
-	case "kor":
-		return "ko"
-	case "kua":
-		return "kj"
-	case "kur":
-		return "ku"
-	case "lao":
-		return "lo"
-	case "lat":
-		return "la"
-	case "lav":
-		return "lv"
-	case "lim":
-		return "li"
-	case "lin":
-		return "ln"
-	case "lit":
-		return "lt"
-	case "ltz":
-		return "lb"
-	case "lub":
-		return "lu"
-	case "lug":
-		return "lg"
-	case "mac":
-		return "mk"
-	case "mah":
-		return "mh"
-	case "mal":
-		return "ml"
-	case "mao":
-		return "mi"
-	case "mar":
-		return "mr"
-	case "may":
-		return "ms"
-	case "mkd":
-		return "mk"
-	case "mlg":
-		return "mg"
-	case "mlt":
-		return "mt"
-	case "mon":
-		return "mn"
-	case "mri":
-		return "mi"
-	case "msa":
-		return "ms"
-	case "mya":
-		return "my"
-	case "nau":
-		return "na"
-	case "nav":
-		return "nv"
-	case "nbl":
-		return "nr"
-	case "nde":
-		return "nd"
-	case "ndo":
-		return "ng"
-	case "nep":
-		return "ne"
-	case "nld":
-		return "nl"
-	case "nno":
-		return "nn"
-	case "nob":
-		return "nb"
-	case "nor":
-		return "no"
-	case "nya":
-		return "ny"
-	case "oci":
-		return "oc"
-	case "oji":
-		return "oj"
-	case "ori":
-		return "or"
-	case "orm":
-		return "om"
-	case "oss":
-		return "os"
-	case "pan":
-		return "pa"
-	case "per":
-		return "fa"
-	case "pli":
-		return "pi"
-	case "pol":
-		return "pl"
-	case "por":
-		return "pt"
-	case "pus":
-		return "ps"
-	case "que":
-		return "qu"
-	case "roh":
-		return "rm"
-	case "ron":
-		return "ro"
-	case "rum":
-		return "ro"
-	case "run":
-		return "rn"
-	case "rus":
-		return "ru"
-	case "sag":
-		return "sg"
-	case "san":
-		return "sa"
-	case "sin":
-		return "si"
-	case "slk":
-		return "sk"
-	case "slo":
-		return "sk"
-	case "slv":
-		return "sl"
-	case "sme":
-		return "se"
-	case "smo":
-		return "sm"
-	case "sna":
-		return "sn"
-	case "snd":
-		return "sd"
-	case "som":
-		return "so"
-	case "sot":
-		return "st"
-	case "spa":
-		return "es"
-	case "sqi":
-		return "sq"
-	case "srd":
-		return "sc"
-	case "srp":
-		return "sr"
-	case "ssw":
-		return "ss"
-	case "sun":
-		return "su"
-	case "swa":
-		return "sw"
-	case "swe":
-		return "sv"
-	case "tah":
-		return "ty"
-	case "tam":
-		return "ta"
-	case "tat":
-		return "tt"
-	case "tel":
-		return "te"
-	case "tgk":
-		return "tg"
-	case "tgl":
-		return "tl"
-	case "tha":
-		return "th"
-	case "tib":
-		return "bo"
-	case "tir":
-		return "ti"
-	case "ton":
-		return "to"
-	case "tsn":
-		return "tn"
-	case "tso":
-		return "ts"
-	case "tuk":
-		return "tk"
-	case "tur":
-		return "tr"
-	case "twi":
-		return "tw"
-	case "uig":
-		return "ug"
-	case "ukr":
-		return "uk"
-	case "urd":
-		return "ur"
-	case "uzb":
-		return "uz"
-	case "ven":
-		return "ve"
-	case "vie":
-		return "vi"
-	case "vol":
-		return "vo"
-	case "wel":
-		return "cy"
-	case "wln":
-		return "wa"
-	case "wol":
-		return "wo"
-	case "xho":
-		return "xh"
-	case "yid":
-		return "yi"
-	case "yor":
-		return "yo"
-	case "zha":
-		return "za"
-	case "zho":
-		return "zh"
-	case "zul":
-		return "zu"
-	default:
-		return ""
+// SetName sets the contributor's escaped organization name, or their escaped given name, surname and suffix.
+func (c *Contributor) SetName(name string, mappings *Mappings) {
+
+	if mappings.IsOrganization(name) {
+		c.Organization = escapeXML(strings.Join(strings.Fields(name), " "))
+		return
 	}
+
+	personName := ParseName(name, mappings.Names)
+	c.GivenName = escapeXML(personName.GivenName)
+	c.Surname = escapeXML(personName.Surname)
+	c.Suffix = escapeXML(personName.Suffix)
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+)
+
+// readCSVRows reads the rows of a csv file after its header row. Every row must have the given number of fields.
+func readCSVRows(path string, fields int) ([][]string, error) {
+
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = fields
+
+	// Skip the header row.
+	_, err = r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ReadAll()
+}
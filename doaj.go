@@ -5,10 +5,12 @@ package main
 import (
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"log"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -26,7 +28,7 @@ type DOAJRecord struct {
 	DOAJDocumentType      *DOAJDocumentType      `xml:" documentType,omitempty" json:"documentType,omitempty"`
 	DOAJDoi               *DOAJDoi               `xml:" doi,omitempty" json:"doi,omitempty"`
 	DOAJEndPage           *DOAJEndPage           `xml:" endPage,omitempty" json:"endPage,omitempty"`
-	DOAJFullTextURL       *DOAJFullTextURL       `xml:" fullTextUrl,omitempty" json:"fullTextUrl,omitempty"`
+	DOAJFullTextURL       []*DOAJFullTextURL     `xml:" fullTextUrl,omitempty" json:"fullTextUrl,omitempty"`
 	DOAJIssn              *DOAJIssn              `xml:" issn,omitempty" json:"issn,omitempty"`
 	DOAJIssue             *DOAJIssue             `xml:" issue,omitempty" json:"issue,omitempty"`
 	DOAJJournalTitle      *DOAJJournalTitle      `xml:" journalTitle,omitempty" json:"journalTitle,omitempty"`
@@ -36,7 +38,7 @@ type DOAJRecord struct {
 	DOAJPublisher         *DOAJPublisher         `xml:" publisher,omitempty" json:"publisher,omitempty"`
 	DOAJPublisherRecordID *DOAJPublisherRecordID `xml:" publisherRecordId,omitempty" json:"publisherRecordId,omitempty"`
 	DOAJStartPage         *DOAJStartPage         `xml:" startPage,omitempty" json:"startPage,omitempty"`
-	DOAJTitle             *DOAJTitle             `xml:" title,omitempty" json:"title,omitempty"`
+	DOAJTitle             []*DOAJTitle           `xml:" title,omitempty" json:"title,omitempty"`
 	DOAJVolume            *DOAJVolume            `xml:" volume,omitempty" json:"volume,omitempty"`
 }
 
@@ -92,7 +94,14 @@ type DOAJDoi struct {
 
 // DOAJPublisherRecordID is the publisher record id
 type DOAJPublisherRecordID struct {
-	Text int8 `xml:",chardata" json:",omitempty"`
+	Text int64 `xml:",chardata" json:",omitempty"`
+}
+
+// UnmarshalXML decodes a publisher record ID, reporting the position of invalid IDs.
+func (id *DOAJPublisherRecordID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var err error
+	id.Text, err = decodeID(d, start)
+	return err
 }
 
 // DOAJDocumentType is the document type
@@ -113,9 +122,10 @@ type DOAJAuthors struct {
 
 // DOAJAuthor is an article author
 type DOAJAuthor struct {
-	DOAJAffiliationID *DOAJAffiliationID `xml:" affiliationId,omitempty" json:"affiliationId,omitempty"`
-	DOAJEmail         *DOAJEmail         `xml:" email,omitempty" json:"email,omitempty"`
-	DOAJName          *DOAJName          `xml:" name,omitempty" json:"name,omitempty"`
+	DOAJAffiliationID []*DOAJAffiliationID `xml:" affiliationId,omitempty" json:"affiliationId,omitempty"`
+	DOAJEmail         *DOAJEmail           `xml:" email,omitempty" json:"email,omitempty"`
+	DOAJName          *DOAJName            `xml:" name,omitempty" json:"name,omitempty"`
+	DOAJORCID         *DOAJORCID           `xml:"orcid_id,omitempty" json:"orcid_id,omitempty"`
 }
 
 // DOAJName is the author name
@@ -123,6 +133,11 @@ type DOAJName struct {
 	Text string `xml:",chardata" json:",omitempty"`
 }
 
+// DOAJORCID is the author ORCID
+type DOAJORCID struct {
+	Text string `xml:",chardata" json:",omitempty"`
+}
+
 // DOAJEmail is the author email
 type DOAJEmail struct {
 	Text string `xml:",chardata" json:",omitempty"`
@@ -130,7 +145,14 @@ type DOAJEmail struct {
 
 // DOAJAffiliationID is the affiliation ID
 type DOAJAffiliationID struct {
-	Text int8 `xml:",chardata" json:",omitempty"`
+	Text int64 `xml:",chardata" json:",omitempty"`
+}
+
+// UnmarshalXML decodes an affiliation ID, reporting the position of invalid IDs.
+func (id *DOAJAffiliationID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var err error
+	id.Text, err = decodeID(d, start)
+	return err
 }
 
 // DOAJAffiliationsList holds the affiliations
@@ -140,10 +162,58 @@ type DOAJAffiliationsList struct {
 
 // DOAJAffiliationName maps ids to names
 type DOAJAffiliationName struct {
-	AttrAffiliationID int8   `xml:" affiliationId,attr"  json:",omitempty"`
+	AttrAffiliationID int64  `xml:" affiliationId,attr"  json:",omitempty"`
 	Text              string `xml:",chardata" json:",omitempty"`
 }
 
+// UnmarshalXML decodes an affiliation name, reporting the position of an invalid affiliation ID.
+func (a *DOAJAffiliationName) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+
+	line, column := d.InputPos()
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "affiliationId" {
+			id, err := parseID(attr.Value, attr.Name.Local, line, column)
+			if err != nil {
+				return err
+			}
+			a.AttrAffiliationID = id
+		}
+	}
+
+	return d.DecodeElement(&a.Text, &start)
+}
+
+// decodeID decodes the text of an ID element.
+func decodeID(d *xml.Decoder, start xml.StartElement) (int64, error) {
+
+	line, column := d.InputPos()
+
+	var text string
+	err := d.DecodeElement(&text, &start)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseID(text, start.Name.Local, line, column)
+}
+
+// parseID parses an ID. Empty IDs are 0. The error for an invalid ID gives the line and column where it was found.
+func parseID(text, name string, line, column int) (int64, error) {
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %v, column %v: %v \"%v\" is not a valid ID", line, column, name, text)
+	}
+
+	return id, nil
+}
+
 // DOAJAbstract is the article abstract
 type DOAJAbstract struct {
 	AttrLanguage string `xml:" language,attr"  json:",omitempty"`
@@ -188,6 +258,43 @@ func LoadDOAJ(xmlFilePath string) (*DOAJRecords, error) {
 	return records, nil
 }
 
+// Title returns the text of the record's first title, or an empty string if it has none.
+func (r *DOAJRecord) Title() string {
+
+	if len(r.DOAJTitle) == 0 {
+		return ""
+	}
+
+	return r.DOAJTitle[0].Text
+}
+
+// LanguageCode returns the ISO 639-1 code of the record's language, or an empty string if it has none or it can't be mapped.
+func (r *DOAJRecord) LanguageCode() string {
+
+	if r.DOAJLanguage == nil {
+		return ""
+	}
+
+	code, _ := LanguageCode(r.DOAJLanguage.Text)
+	return code
+}
+
+// LandingPageURL returns the html full text URL, or the first full text URL if none are html.
+func (r *DOAJRecord) LandingPageURL() string {
+
+	if len(r.DOAJFullTextURL) == 0 {
+		return ""
+	}
+
+	for _, fullTextURL := range r.DOAJFullTextURL {
+		if strings.EqualFold(fullTextURL.AttrFormat, "html") {
+			return fullTextURL.Text
+		}
+	}
+
+	return r.DOAJFullTextURL[0].Text
+}
+
 // Validate looks through a DOAJ struct to ensure no records would fail crossref validation.
 func (r *DOAJRecords) Validate() bool {
 
@@ -196,40 +303,156 @@ func (r *DOAJRecords) Validate() bool {
 	for _, record := range r.DOAJRecords {
 		err := record.validate()
 		if err != nil {
-			log.Printf("\"%v\", URL: %v\n", record.DOAJTitle.Text, record.DOAJFullTextURL.Text)
+			log.Printf("\"%v\", URL: %v\n", record.Title(), record.LandingPageURL())
 			log.Println(err)
 			ok = false
+			continue
+		}
+		for _, warning := range record.warnings() {
+			log.Printf("Warning: \"%v\", URL: %v\n", record.Title(), record.LandingPageURL())
+			log.Println(warning)
 		}
 	}
 
 	return ok
 }
 
+// dateLayouts are the accepted publication date formats: a full date, a year and month, or a year.
+var dateLayouts = []string{"2006-01-02", "2006-01", "2006"}
+
+// ParseDate parses a full or partial publication date. It returns the date and the layout which matched it.
+func ParseDate(date string) (time.Time, string, error) {
+
+	date = strings.TrimSpace(date)
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, date)
+		if err == nil {
+			return t, layout, nil
+		}
+	}
+
+	return time.Time{}, "", fmt.Errorf("unable to parse date \"%v\", expected YYYY-MM-DD, YYYY-MM or YYYY", date)
+}
+
+// warnings returns problems with the record which don't stop it being deposited.
+func (r *DOAJRecord) warnings() []string {
+
+	warnings := []string{}
+
+	t, _, err := ParseDate(r.DOAJPublicationDate.Text)
+	if err == nil {
+		if t.After(time.Now()) {
+			warnings = append(warnings, fmt.Sprintf("publication date %v is in the future", r.DOAJPublicationDate.Text))
+		}
+		if t.Year() < 1665 {
+			warnings = append(warnings, fmt.Sprintf("publication date %v is before 1665", r.DOAJPublicationDate.Text))
+		}
+	}
+
+	// Invalid ORCIDs are left out of the deposit.
+	for _, author := range r.DOAJAuthors.DOAJAuthor {
+		_, orcid := SplitORCID(author.DOAJName.Text)
+		if author.DOAJORCID != nil && strings.TrimSpace(author.DOAJORCID.Text) != "" {
+			orcid = author.DOAJORCID.Text
+		}
+		if orcid == "" {
+			continue
+		}
+		if _, err := NormalizeORCID(orcid); err != nil {
+			warnings = append(warnings, fmt.Sprintf("author \"%v\": %v, so it is left out", author.DOAJName.Text, err))
+		}
+	}
+
+	return warnings
+}
+
 func (r *DOAJRecord) validate() error {
 
 	//Check if publication date is not empty and parse-able.
 	if strings.TrimSpace(r.DOAJPublicationDate.Text) == "" {
 		return errors.New("publication date is empty")
 	}
-	_, err := time.Parse("2006-01-02", r.DOAJPublicationDate.Text)
+	_, _, err := ParseDate(r.DOAJPublicationDate.Text)
 	if err != nil {
 		return err
 	}
 
-	//Check if URL not empty and parse-able.
-	if strings.TrimSpace(r.DOAJFullTextURL.Text) == "" {
-		return errors.New("fulltext URL is empty")
+	//Check if URLs not empty and parse-able.
+	if len(r.DOAJFullTextURL) == 0 {
+		return errors.New("no fulltext URL")
 	}
-	_, err = url.Parse(r.DOAJFullTextURL.Text)
-	if err != nil {
+	for _, fullTextURL := range r.DOAJFullTextURL {
+		if strings.TrimSpace(fullTextURL.Text) == "" {
+			return errors.New("fulltext URL is empty")
+		}
+		_, err = url.Parse(fullTextURL.Text)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check if the record has no title.
+	if strings.TrimSpace(r.Title()) == "" {
+		return errors.New("title is empty")
+	}
+
+	// Check if the record's language and the languages of its titles have ISO 639-1 codes.
+	if r.DOAJLanguage == nil || strings.TrimSpace(r.DOAJLanguage.Text) == "" {
+		return errors.New("language is empty")
+	}
+	if _, err := LanguageCode(r.DOAJLanguage.Text); err != nil {
 		return err
 	}
+	for _, title := range r.DOAJTitle {
+		if title.AttrLanguage == "" {
+			continue
+		}
+		if _, err := LanguageCode(title.AttrLanguage); err != nil {
+			return fmt.Errorf("title \"%v\": %v", title.Text, err)
+		}
+	}
 
 	// Check if the record has no authors.
 	if len(r.DOAJAuthors.DOAJAuthor) == 0 {
 		return errors.New("no authors")
 	}
 
+	// Check if every author has a name, apart from an ORCID or role written in the name field.
+	for i, author := range r.DOAJAuthors.DOAJAuthor {
+		name := ""
+		if author.DOAJName != nil {
+			name, _ = SplitORCID(author.DOAJName.Text)
+			name, _ = SplitRole(name)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("author %v has no name", i+1)
+		}
+	}
+
+	// Check if every author affiliation ID is in the affiliations list.
+	affiliationIDs := make(map[int64]bool)
+	if r.DOAJAffiliationsList != nil {
+		for _, affiliation := range r.DOAJAffiliationsList.DOAJAffiliationName {
+			affiliationIDs[affiliation.AttrAffiliationID] = true
+		}
+	}
+	for _, author := range r.DOAJAuthors.DOAJAuthor {
+		for _, affiliationID := range author.DOAJAffiliationID {
+			if !affiliationIDs[affiliationID.Text] {
+				return fmt.Errorf("affiliation ID %v of author \"%v\" is not in the affiliations list", affiliationID.Text, author.DOAJName.Text)
+			}
+		}
+	}
+
+	// Check if the first page comes before the last page.
+	pages := CreatePages(r)
+	if pages.FirstPage != "" && pages.LastPage != "" {
+		if order, ok := comparePages(pages.FirstPage, pages.LastPage); ok && order > 0 {
+			return fmt.Errorf("first page %v is after last page %v", pages.FirstPage, pages.LastPage)
+		}
+	}
+
 	// Check if the record has no journal title.
 	if strings.TrimSpace(r.DOAJJournalTitle.Text) == "" {
 		return errors.New("journal title is empty")
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestLoadDOAJLargeIDs(t *testing.T) {
+
+	records, err := LoadDOAJ("testdata/large-ids.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records.DOAJRecords) != 1 {
+		t.Fatalf("got %v records, want 1", len(records.DOAJRecords))
+	}
+	record := records.DOAJRecords[0]
+
+	if got := record.DOAJPublisherRecordID.Text; got != 1142 {
+		t.Errorf("publisherRecordId = %v, want 1142", got)
+	}
+
+	want := []int64{128, 40000}
+	for i, author := range record.DOAJAuthors.DOAJAuthor {
+		if got := author.DOAJAffiliationID[0].Text; got != want[i] {
+			t.Errorf("author %v affiliationId = %v, want %v", i, got, want[i])
+		}
+	}
+	for i, affiliation := range record.DOAJAffiliationsList.DOAJAffiliationName {
+		if got := affiliation.AttrAffiliationID; got != want[i] {
+			t.Errorf("affiliationName %v affiliationId = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestCreateTemplateDataLargeIDs(t *testing.T) {
+
+	mappings, err := LoadConfig("testdata/config.json", "4.4.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := LoadDOAJ("testdata/large-ids.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	templateData := CreateTemplateData("Depositor", "doi@review.ca", "Registrant", "4.4.1", mappings, records)
+	if len(templateData.Journals) != 1 || len(templateData.Journals[0].Articles) != 1 {
+		t.Fatalf("got %v journals, want 1 with 1 article", len(templateData.Journals))
+	}
+	article := templateData.Journals[0].Articles[0]
+
+	if article.DOI != "10.11000/review1142" {
+		t.Errorf("DOI = %v, want 10.11000/review1142", article.DOI)
+	}
+
+	want := []string{"Carleton University", "University of Ottawa"}
+	if len(article.Contributors) != len(want) {
+		t.Fatalf("got %v contributors, want %v", len(article.Contributors), len(want))
+	}
+	for i, contributor := range article.Contributors {
+		if len(contributor.Affiliations) != 1 || contributor.Affiliations[0].Name != want[i] {
+			t.Errorf("contributor %v affiliations = %+v, want %v", i, contributor.Affiliations, want[i])
+		}
+	}
+}
+
+func TestDecodeInvalidIDs(t *testing.T) {
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"<records>\n<record>\n<publisherRecordId>11a</publisherRecordId>\n</record>\n</records>",
+			"line 3, column 20: publisherRecordId \"11a\" is not a valid ID",
+		},
+		{
+			"<records>\n<record>\n<authors>\n<author><affiliationId>1.5</affiliationId></author>\n</authors>\n</record>\n</records>",
+			"line 4, column 24: affiliationId \"1.5\" is not a valid ID",
+		},
+		{
+			"<records>\n<record>\n<affiliationsList>\n  <affiliationName affiliationId=\"x\">Carleton University</affiliationName>\n</affiliationsList>\n</record>\n</records>",
+			"line 4, column 38: affiliationId \"x\" is not a valid ID",
+		},
+	}
+
+	for _, test := range tests {
+		records := new(DOAJRecords)
+		err := xml.NewDecoder(strings.NewReader(test.input)).Decode(records)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("decoding %q: got error %v, want %v", test.input, err, test.want)
+		}
+	}
+}
+
+func TestParseID(t *testing.T) {
+
+	id, err := parseID(" 9223372036854775807 ", "publisherRecordId", 1, 1)
+	if err != nil || id != 9223372036854775807 {
+		t.Errorf("got %v, %v, want 9223372036854775807", id, err)
+	}
+
+	id, err = parseID("", "publisherRecordId", 1, 1)
+	if err != nil || id != 0 {
+		t.Errorf("got %v, %v for an empty ID, want 0", id, err)
+	}
+
+	_, err = parseID("12 34", "affiliationId", 7, 3)
+	if err == nil || err.Error() != "line 7, column 3: affiliationId \"12 34\" is not a valid ID" {
+		t.Errorf("got error %v", err)
+	}
+}
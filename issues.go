@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IssueKey identifies a journal issue.
+type IssueKey struct {
+	JournalTitle string
+	Volume       string
+	Issue        string
+}
+
+// IssueMetadata holds metadata about a journal issue which is not in the DOAJ export, like special issue titles, guest editors and issue dates.
+type IssueMetadata struct {
+	JournalTitle     string   `json:"journalTitle"`
+	Volume           string   `json:"volume"`
+	Issue            string   `json:"issue"`
+	Title            string   `json:"title"`
+	SpecialNumbering string   `json:"specialNumbering"`
+	Editors          []string `json:"editors"`
+	PublicationDate  string   `json:"publicationDate"`
+	PrintDate        string   `json:"printDate"`
+}
+
+// LoadIssues loads issue metadata from a JSON file, keyed by journal title, volume and issue.
+func LoadIssues(issuesFilePath string) (map[IssueKey]IssueMetadata, error) {
+
+	issues := make(map[IssueKey]IssueMetadata)
+	issueList := []IssueMetadata{}
+
+	absoluteIssuesFilePath, err := filepath.Abs(issuesFilePath)
+	if err != nil {
+		return issues, err
+	}
+
+	issuesFile, err := os.Open(absoluteIssuesFilePath)
+	if err != nil {
+		return issues, err
+	}
+	defer issuesFile.Close()
+
+	issuesDecoder := json.NewDecoder(issuesFile)
+	err = issuesDecoder.Decode(&issueList)
+	if err != nil {
+		return issues, err
+	}
+
+	for _, issue := range issueList {
+		key := IssueKey{
+			JournalTitle: strings.TrimSpace(issue.JournalTitle),
+			Volume:       strings.TrimSpace(issue.Volume),
+			Issue:        strings.TrimSpace(issue.Issue),
+		}
+		if _, ok := issues[key]; ok {
+			return issues, fmt.Errorf("duplicate issue metadata for \"%v\" volume %v issue %v", key.JournalTitle, key.Volume, key.Issue)
+		}
+		issues[key] = issue
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// language is a language with an ISO 639-1 code, its ISO 639-2 bibliographic and terminologic codes, and its English names.
+type language struct {
+	alpha2        string
+	bibliographic string
+	terminologic  string
+	names         []string
+}
+
+// languages are all the languages with an ISO 639-1 code, which Crossref uses in language attributes.
+// The ISO 639-3 code of each is the same as its ISO 639-2 terminologic code.
+var languages = []language{
+	{"aa", "aar", "aar", []string{"Afar"}},
+	{"ab", "abk", "abk", []string{"Abkhazian"}},
+	{"ae", "ave", "ave", []string{"Avestan"}},
+	{"af", "afr", "afr", []string{"Afrikaans"}},
+	{"ak", "aka", "aka", []string{"Akan"}},
+	{"am", "amh", "amh", []string{"Amharic"}},
+	{"an", "arg", "arg", []string{"Aragonese"}},
+	{"ar", "ara", "ara", []string{"Arabic"}},
+	{"as", "asm", "asm", []string{"Assamese"}},
+	{"av", "ava", "ava", []string{"Avaric"}},
+	{"ay", "aym", "aym", []string{"Aymara"}},
+	{"az", "aze", "aze", []string{"Azerbaijani"}},
+	{"ba", "bak", "bak", []string{"Bashkir"}},
+	{"be", "bel", "bel", []string{"Belarusian"}},
+	{"bg", "bul", "bul", []string{"Bulgarian"}},
+	{"bh", "bih", "bih", []string{"Bihari languages"}},
+	{"bi", "bis", "bis", []string{"Bislama"}},
+	{"bm", "bam", "bam", []string{"Bambara"}},
+	{"bn", "ben", "ben", []string{"Bengali", "Bangla"}},
+	{"bo", "tib", "bod", []string{"Tibetan"}},
+	{"br", "bre", "bre", []string{"Breton"}},
+	{"bs", "bos", "bos", []string{"Bosnian"}},
+	{"ca", "cat", "cat", []string{"Catalan", "Valencian"}},
+	{"ce", "che", "che", []string{"Chechen"}},
+	{"ch", "cha", "cha", []string{"Chamorro"}},
+	{"co", "cos", "cos", []string{"Corsican"}},
+	{"cr", "cre", "cre", []string{"Cree"}},
+	{"cs", "cze", "ces", []string{"Czech"}},
+	{"cu", "chu", "chu", []string{"Church Slavic", "Old Slavonic", "Church Slavonic", "Old Bulgarian", "Old Church Slavonic"}},
+	{"cv", "chv", "chv", []string{"Chuvash"}},
+	{"cy", "wel", "cym", []string{"Welsh"}},
+	{"da", "dan", "dan", []string{"Danish"}},
+	{"de", "ger", "deu", []string{"German"}},
+	{"dv", "div", "div", []string{"Divehi", "Dhivehi", "Maldivian"}},
+	{"dz", "dzo", "dzo", []string{"Dzongkha"}},
+	{"ee", "ewe", "ewe", []string{"Ewe"}},
+	{"el", "gre", "ell", []string{"Greek, Modern (1453-)", "Greek, Modern", "Greek"}},
+	{"en", "eng", "eng", []string{"English"}},
+	{"eo", "epo", "epo", []string{"Esperanto"}},
+	{"es", "spa", "spa", []string{"Spanish", "Castilian"}},
+	{"et", "est", "est", []string{"Estonian"}},
+	{"eu", "baq", "eus", []string{"Basque"}},
+	{"fa", "per", "fas", []string{"Persian"}},
+	{"ff", "ful", "ful", []string{"Fulah"}},
+	{"fi", "fin", "fin", []string{"Finnish"}},
+	{"fj", "fij", "fij", []string{"Fijian"}},
+	{"fo", "fao", "fao", []string{"Faroese"}},
+	{"fr", "fre", "fra", []string{"French"}},
+	{"fy", "fry", "fry", []string{"Western Frisian"}},
+	{"ga", "gle", "gle", []string{"Irish"}},
+	{"gd", "gla", "gla", []string{"Gaelic", "Scottish Gaelic"}},
+	{"gl", "glg", "glg", []string{"Galician"}},
+	{"gn", "grn", "grn", []string{"Guarani"}},
+	{"gu", "guj", "guj", []string{"Gujarati"}},
+	{"gv", "glv", "glv", []string{"Manx"}},
+	{"ha", "hau", "hau", []string{"Hausa"}},
+	{"he", "heb", "heb", []string{"Hebrew"}},
+	{"hi", "hin", "hin", []string{"Hindi"}},
+	{"ho", "hmo", "hmo", []string{"Hiri Motu"}},
+	{"hr", "hrv", "hrv", []string{"Croatian"}},
+	{"ht", "hat", "hat", []string{"Haitian", "Haitian Creole"}},
+	{"hu", "hun", "hun", []string{"Hungarian"}},
+	{"hy", "arm", "hye", []string{"Armenian"}},
+	{"hz", "her", "her", []string{"Herero"}},
+	{"ia", "ina", "ina", []string{"Interlingua (International Auxiliary Language Association)", "Interlingua"}},
+	{"id", "ind", "ind", []string{"Indonesian"}},
+	{"ie", "ile", "ile", []string{"Interlingue", "Occidental"}},
+	{"ig", "ibo", "ibo", []string{"Igbo"}},
+	{"ii", "iii", "iii", []string{"Sichuan Yi", "Nuosu"}},
+	{"ik", "ipk", "ipk", []string{"Inupiaq"}},
+	{"io", "ido", "ido", []string{"Ido"}},
+	{"is", "ice", "isl", []string{"Icelandic"}},
+	{"it", "ita", "ita", []string{"Italian"}},
+	{"iu", "iku", "iku", []string{"Inuktitut"}},
+	{"ja", "jpn", "jpn", []string{"Japanese"}},
+	{"jv", "jav", "jav", []string{"Javanese"}},
+	{"ka", "geo", "kat", []string{"Georgian"}},
+	{"kg", "kon", "kon", []string{"Kongo"}},
+	{"ki", "kik", "kik", []string{"Kikuyu", "Gikuyu"}},
+	{"kj", "kua", "kua", []string{"Kuanyama", "Kwanyama"}},
+	{"kk", "kaz", "kaz", []string{"Kazakh"}},
+	{"kl", "kal", "kal", []string{"Kalaallisut", "Greenlandic"}},
+	{"km", "khm", "khm", []string{"Central Khmer"}},
+	{"kn", "kan", "kan", []string{"Kannada"}},
+	{"ko", "kor", "kor", []string{"Korean"}},
+	{"kr", "kau", "kau", []string{"Kanuri"}},
+	{"ks", "kas", "kas", []string{"Kashmiri"}},
+	{"ku", "kur", "kur", []string{"Kurdish"}},
+	{"kv", "kom", "kom", []string{"Komi"}},
+	{"kw", "cor", "cor", []string{"Cornish"}},
+	{"ky", "kir", "kir", []string{"Kirghiz", "Kyrgyz"}},
+	{"la", "lat", "lat", []string{"Latin"}},
+	{"lb", "ltz", "ltz", []string{"Luxembourgish", "Letzeburgesch"}},
+	{"lg", "lug", "lug", []string{"Ganda"}},
+	{"li", "lim", "lim", []string{"Limburgan", "Limburger", "Limburgish"}},
+	{"ln", "lin", "lin", []string{"Lingala"}},
+	{"lo", "lao", "lao", []string{"Lao"}},
+	{"lt", "lit", "lit", []string{"Lithuanian"}},
+	{"lu", "lub", "lub", []string{"Luba-Katanga"}},
+	{"lv", "lav", "lav", []string{"Latvian"}},
+	{"mg", "mlg", "mlg", []string{"Malagasy"}},
+	{"mh", "mah", "mah", []string{"Marshallese"}},
+	{"mi", "mao", "mri", []string{"Maori"}},
+	{"mk", "mac", "mkd", []string{"Macedonian"}},
+	{"ml", "mal", "mal", []string{"Malayalam"}},
+	{"mn", "mon", "mon", []string{"Mongolian"}},
+	{"mr", "mar", "mar", []string{"Marathi"}},
+	{"ms", "may", "msa", []string{"Malay"}},
+	{"mt", "mlt", "mlt", []string{"Maltese"}},
+	{"my", "bur", "mya", []string{"Burmese"}},
+	{"na", "nau", "nau", []string{"Nauru"}},
+	{"nb", "nob", "nob", []string{"Bokmål, Norwegian", "Norwegian Bokmål"}},
+	{"nd", "nde", "nde", []string{"Ndebele, North", "North Ndebele"}},
+	{"ne", "nep", "nep", []string{"Nepali"}},
+	{"ng", "ndo", "ndo", []string{"Ndonga"}},
+	{"nl", "dut", "nld", []string{"Dutch", "Flemish"}},
+	{"nn", "nno", "nno", []string{"Norwegian Nynorsk", "Nynorsk, Norwegian"}},
+	{"no", "nor", "nor", []string{"Norwegian"}},
+	{"nr", "nbl", "nbl", []string{"Ndebele, South", "South Ndebele"}},
+	{"nv", "nav", "nav", []string{"Navajo", "Navaho"}},
+	{"ny", "nya", "nya", []string{"Chichewa", "Chewa", "Nyanja"}},
+	{"oc", "oci", "oci", []string{"Occitan (post 1500)", "Provençal", "Occitan"}},
+	{"oj", "oji", "oji", []string{"Ojibwa"}},
+	{"om", "orm", "orm", []string{"Oromo"}},
+	{"or", "ori", "ori", []string{"Oriya"}},
+	{"os", "oss", "oss", []string{"Ossetian", "Ossetic"}},
+	{"pa", "pan", "pan", []string{"Panjabi", "Punjabi"}},
+	{"pi", "pli", "pli", []string{"Pali"}},
+	{"pl", "pol", "pol", []string{"Polish"}},
+	{"ps", "pus", "pus", []string{"Pushto", "Pashto"}},
+	{"pt", "por", "por", []string{"Portuguese"}},
+	{"qu", "que", "que", []string{"Quechua"}},
+	{"rm", "roh", "roh", []string{"Romansh"}},
+	{"rn", "run", "run", []string{"Rundi"}},
+	{"ro", "rum", "ron", []string{"Romanian", "Moldavian", "Moldovan"}},
+	{"ru", "rus", "rus", []string{"Russian"}},
+	{"rw", "kin", "kin", []string{"Kinyarwanda"}},
+	{"sa", "san", "san", []string{"Sanskrit"}},
+	{"sc", "srd", "srd", []string{"Sardinian"}},
+	{"sd", "snd", "snd", []string{"Sindhi"}},
+	{"se", "sme", "sme", []string{"Northern Sami"}},
+	{"sg", "sag", "sag", []string{"Sango"}},
+	{"si", "sin", "sin", []string{"Sinhala", "Sinhalese"}},
+	{"sk", "slo", "slk", []string{"Slovak"}},
+	{"sl", "slv", "slv", []string{"Slovenian"}},
+	{"sm", "smo", "smo", []string{"Samoan"}},
+	{"sn", "sna", "sna", []string{"Shona"}},
+	{"so", "som", "som", []string{"Somali"}},
+	{"sq", "alb", "sqi", []string{"Albanian"}},
+	{"sr", "srp", "srp", []string{"Serbian"}},
+	{"ss", "ssw", "ssw", []string{"Swati"}},
+	{"st", "sot", "sot", []string{"Sotho, Southern"}},
+	{"su", "sun", "sun", []string{"Sundanese"}},
+	{"sv", "swe", "swe", []string{"Swedish"}},
+	{"sw", "swa", "swa", []string{"Swahili"}},
+	{"ta", "tam", "tam", []string{"Tamil"}},
+	{"te", "tel", "tel", []string{"Telugu"}},
+	{"tg", "tgk", "tgk", []string{"Tajik"}},
+	{"th", "tha", "tha", []string{"Thai"}},
+	{"ti", "tir", "tir", []string{"Tigrinya"}},
+	{"tk", "tuk", "tuk", []string{"Turkmen"}},
+	{"tl", "tgl", "tgl", []string{"Tagalog"}},
+	{"tn", "tsn", "tsn", []string{"Tswana"}},
+	{"to", "ton", "ton", []string{"Tonga (Tonga Islands)", "Tonga"}},
+	{"tr", "tur", "tur", []string{"Turkish"}},
+	{"ts", "tso", "tso", []string{"Tsonga"}},
+	{"tt", "tat", "tat", []string{"Tatar"}},
+	{"tw", "twi", "twi", []string{"Twi"}},
+	{"ty", "tah", "tah", []string{"Tahitian"}},
+	{"ug", "uig", "uig", []string{"Uighur", "Uyghur"}},
+	{"uk", "ukr", "ukr", []string{"Ukrainian"}},
+	{"ur", "urd", "urd", []string{"Urdu"}},
+	{"uz", "uzb", "uzb", []string{"Uzbek"}},
+	{"ve", "ven", "ven", []string{"Venda"}},
+	{"vi", "vie", "vie", []string{"Vietnamese"}},
+	{"vo", "vol", "vol", []string{"Volapük"}},
+	{"wa", "wln", "wln", []string{"Walloon"}},
+	{"wo", "wol", "wol", []string{"Wolof"}},
+	{"xh", "xho", "xho", []string{"Xhosa"}},
+	{"yi", "yid", "yid", []string{"Yiddish"}},
+	{"yo", "yor", "yor", []string{"Yoruba"}},
+	{"za", "zha", "zha", []string{"Zhuang", "Chuang"}},
+	{"zh", "chi", "zho", []string{"Chinese"}},
+	{"zu", "zul", "zul", []string{"Zulu"}},
+}
+
+// individualLanguages maps the ISO 639-3 codes of common individual languages to the ISO 639-1 code of their macrolanguage.
+var individualLanguages = map[string]string{
+	"als": "sq", "arb": "ar", "azj": "az", "cmn": "zh", "ekk": "et", "khk": "mn", "lvs": "lv",
+	"pes": "fa", "plt": "mg", "quz": "qu", "swh": "sw", "uzn": "uz", "ydd": "yi", "zsm": "ms",
+}
+
+// languageIndex maps lowercased codes and names to ISO 639-1 codes.
+var languageIndex = func() map[string]string {
+
+	index := make(map[string]string)
+	for code, alpha2 := range individualLanguages {
+		index[code] = alpha2
+	}
+	for _, l := range languages {
+		index[l.alpha2] = l.alpha2
+		index[l.bibliographic] = l.alpha2
+		index[l.terminologic] = l.alpha2
+		for _, name := range l.names {
+			index[strings.ToLower(name)] = l.alpha2
+		}
+	}
+
+	return index
+}()
+
+// LanguageCode returns the ISO 639-1 code used by Crossref for a language given as an ISO 639-1, ISO 639-2/B,
+// ISO 639-2/T or ISO 639-3 code, or by its English name, like "English". Region subtags, as in "en-GB", are ignored.
+// An error is returned for languages which can't be mapped.
+func LanguageCode(language string) (string, error) {
+
+	key := strings.ToLower(strings.Join(strings.Fields(language), " "))
+	if code, ok := languageIndex[key]; ok {
+		return code, nil
+	}
+
+	if i := strings.IndexAny(key, "-_"); i > 0 {
+		if code, ok := languageIndex[key[:i]]; ok {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("language \"%v\" has no ISO 639-1 code", language)
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"encoding/csv"
 	"flag"
+	"html"
 	"log"
 	"os"
+	"strings"
 	"text/template"
 )
 
@@ -15,6 +17,16 @@ var urlToDOICSVOutputFilePath = flag.String("report", "report.csv", "Path to whi
 var depositorName = flag.String("depositor", "", "Name of the organization registering the DOIs. The name placed in this element should match the name under which a depositing organization has registered with CrossRef.")
 var depositorEmail = flag.String("email", "", "Email address to which batch success and/or error messages are sent. It is recommended that this address be unique to a position within the organization submitting data (e.g. \"doi@...\") rather than unique to a person. In this way, the alias for delivery of this mail can be changed as responsibility for submission of DOI data within the organization changes from one person to another.")
 var registrant = flag.String("registrant", "", "The organization that owns the information being registered.")
+var referencesPath = flag.String("references", "", "Path to a JSON file or a directory of text files holding the reference list for each article.")
+var correctionsFilePath = flag.String("corrections", "", "Path to a csv file of corrections, mapping the URL of each correcting article to the DOI it updates and the update type.")
+var relationsFilePath = flag.String("relations", "", "Path to a csv file of relations, mapping the URL of each article to a relation type and the DOI or URI of the related work.")
+var pairTranslations = flag.Bool("pair-translations", false, "Relate records which share a publisherRecordId but not a language as translations of the first such record.")
+var issuesFilePath = flag.String("issues", "", "Path to a JSON file of issue metadata, like special issue titles and guest editors.")
+var rolesFilePath = flag.String("roles", "", "Path to a csv file of contributor roles, mapping the URL of each article and a contributor's name to their Crossref contributor role.")
+var rorDumpFilePath = flag.String("ror-dump", "", "Path to a ROR data dump JSON file, used to match affiliations to ROR IDs.")
+var schemaVersion = flag.String("schema", "4.4.1", "Crossref deposit schema version, 4.4.1 or 5.3.1. ROR IDs for affiliations are only written with 5.3.1.")
+var reportNotes = flag.Bool("report-notes", false, "Add a Note column to the report csv file, flagging the articles of mixed-language issues.")
+var referencesOnly = flag.Bool("references-only", false, "Write a resource-only deposit which adds reference lists to DOIs that are already registered.")
 
 func main() {
 	flag.Parse()
@@ -28,22 +40,83 @@ func main() {
 	if *registrant == "" {
 		log.Fatalln("registrant required")
 	}
+	if !contains(SchemaVersions, *schemaVersion) {
+		log.Fatalln("schema must be one of", strings.Join(SchemaVersions, ", "))
+	}
 
-	journalConfig, orcids, err := LoadConfig(*configFilePath)
+	mappings, err := LoadConfig(*configFilePath, *schemaVersion)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	if *referencesPath != "" {
+		mappings.References, err = LoadReferences(*referencesPath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	} else if *referencesOnly {
+		log.Fatalln("references required")
+	}
+
+	if *correctionsFilePath != "" {
+		mappings.Corrections, err = LoadCorrections(*correctionsFilePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if *relationsFilePath != "" {
+		mappings.Relations, err = LoadRelations(*relationsFilePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if *issuesFilePath != "" {
+		mappings.Issues, err = LoadIssues(*issuesFilePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if *rolesFilePath != "" {
+		mappings.Roles, err = LoadRoles(*rolesFilePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if *rorDumpFilePath != "" {
+		mappings.RORIndex, err = LoadRORDump(*rorDumpFilePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
 	doajData, err := LoadDOAJ(*doajXMLFilePath)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	doajData = FilterRecords(mappings, doajData)
+
 	if !doajData.Validate() {
 		log.Fatalln("DOAJ input data validation failed.")
 	}
 
-	templateData := CreateTemplateData(*depositorName, *depositorEmail, *registrant, journalConfig, orcids, doajData)
+	if *pairTranslations {
+		for uri, relations := range PairTranslations(mappings, doajData) {
+			mappings.Relations[uri] = append(mappings.Relations[uri], relations...)
+		}
+	}
+
+	templateData := CreateTemplateData(*depositorName, *depositorEmail, *registrant, *schemaVersion, mappings, doajData)
+
+	if len(mappings.RORs) > 0 || mappings.RORIndex != nil {
+		for _, affiliation := range UnmatchedAffiliations(templateData) {
+			log.Println("No ROR ID for affiliation:", affiliation)
+		}
+	}
 
 	output, err := os.Create(*crossrefOutputFilePath)
 	if err != nil {
@@ -57,7 +130,14 @@ func main() {
 	}
 	defer report.Close()
 
-	t := template.Must(template.New("template").Parse(templateSkeleton))
+	skeleton := templateSkeleton
+	if *referencesOnly {
+		skeleton = resourceTemplateSkeleton
+	}
+
+	t := template.Must(template.New("template").Funcs(TemplateFuncs(*schemaVersion)).Parse(skeleton))
+	t = template.Must(t.Parse(contributorsTemplate))
+	t = template.Must(t.Parse(citationListTemplate))
 	err = t.Execute(output, &templateData)
 	if err != nil {
 		log.Fatalln(err)
@@ -65,14 +145,42 @@ func main() {
 
 	w := csv.NewWriter(report)
 
-	err = w.Write([]string{"URI", "DOI"})
+	err = w.Write(reportRow("URI", "DOI", "Note"))
 	if err != nil {
 		log.Fatalln("Error writing to csv:", err)
 	}
 
+	journalDOIs := make(map[string]bool)
+	volumeDOIs := make(map[string]bool)
+
 	for _, journal := range templateData.Journals {
+		if journal.JournalDOI != "" && !journalDOIs[journal.JournalDOI] {
+			journalDOIs[journal.JournalDOI] = true
+			err = w.Write(reportRow(html.UnescapeString(journal.JournalURI), html.UnescapeString(journal.JournalDOI), ""))
+			if err != nil {
+				log.Fatalln("Error writing to csv:", err)
+			}
+		}
+		if journal.VolumeDOI != "" && !volumeDOIs[journal.VolumeDOI] {
+			volumeDOIs[journal.VolumeDOI] = true
+			err = w.Write(reportRow(html.UnescapeString(journal.VolumeURI), html.UnescapeString(journal.VolumeDOI), ""))
+			if err != nil {
+				log.Fatalln("Error writing to csv:", err)
+			}
+		}
+		if journal.IssueDOI != "" {
+			err = w.Write(reportRow(html.UnescapeString(journal.IssueURI), html.UnescapeString(journal.IssueDOI), ""))
+			if err != nil {
+				log.Fatalln("Error writing to csv:", err)
+			}
+		}
+		note := ""
+		if languages := journal.Languages(); len(languages) > 1 {
+			note = "mixed-language issue: " + strings.Join(languages, ", ")
+			log.Printf("Warning: volume %v issue %v of %v is a %v\n", journal.Volume, journal.Issue, journal.FullTitle, note)
+		}
 		for _, article := range journal.Articles {
-			err = w.Write([]string{article.URI, article.DOI})
+			err = w.Write(reportRow(article.URI, article.DOI, note))
 			if err != nil {
 				log.Fatalln("Error writing to csv:", err)
 			}
@@ -87,3 +195,11 @@ func main() {
 	}
 
 }
+
+// reportRow returns a row of the report csv file, which only has a note column with -report-notes.
+func reportRow(uri, doi, note string) []string {
+	if *reportNotes {
+		return []string{uri, doi, note}
+	}
+	return []string{uri, doi}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// titleFaces maps the inline HTML elements allowed in titles to Crossref face markup.
+var titleFaces = map[string]string{
+	"i": "i", "em": "i", "b": "b", "strong": "b", "u": "u",
+	"sub": "sub", "sup": "sup", "sc": "scp",
+}
+
+// abstractFaces maps the inline HTML elements allowed in abstracts to JATS elements.
+var abstractFaces = map[string]string{
+	"i": "jats:italic", "em": "jats:italic", "b": "jats:bold", "strong": "jats:bold", "u": "jats:underline",
+	"sub": "jats:sub", "sup": "jats:sup", "sc": "jats:sc", "p": "jats:p",
+}
+
+// strayLessThan matches a "<" which doesn't start a tag, like the one in "p < 0.05".
+var strayLessThan = regexp.MustCompile(`<([^A-Za-z/!?]|$)`)
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// FaceMarkup converts inline HTML in a title to escaped text with Crossref face markup and MathML.
+func FaceMarkup(title string) string {
+	return convertMarkup(title, titleFaces)
+}
+
+// JATSAbstract converts an abstract with inline HTML to the paragraphs of a JATS abstract.
+func JATSAbstract(abstract string) string {
+
+	converted := convertMarkup(abstract, abstractFaces)
+
+	// Paragraphs are never nested, so each run of text between them is wrapped in a paragraph of its own.
+	out := new(bytes.Buffer)
+	for converted != "" {
+		start := strings.Index(converted, "<jats:p>")
+		if start < 0 {
+			start = len(converted)
+		}
+		if text := strings.TrimSpace(converted[:start]); text != "" {
+			out.WriteString("<jats:p>" + text + "</jats:p>")
+		}
+		converted = converted[start:]
+		if converted == "" {
+			break
+		}
+		end := strings.Index(converted, "</jats:p>") + len("</jats:p>")
+		out.WriteString(converted[:end])
+		converted = converted[end:]
+	}
+
+	return out.String()
+}
+
+// convertMarkup converts text with inline HTML to escaped text with the elements in faces and MathML.
+func convertMarkup(text string, faces map[string]string) string {
+
+	text = strayLessThan.ReplaceAllString(html.UnescapeString(text), "&lt;$1")
+
+	d := xml.NewDecoder(strings.NewReader(text))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	out := new(bytes.Buffer)
+	// open holds the input element names and the names written for them, which are empty for dropped elements.
+	open := []struct{ name, face string }{}
+	math := 0
+
+	for {
+		token, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return escapeXML(strings.TrimSpace(whitespace.ReplaceAllString(text, " ")))
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			face := faces[name]
+			if name == "math" || math > 0 {
+				face = "mml:" + t.Name.Local
+				math++
+			}
+			if face == "" {
+				if name == "br" {
+					out.WriteString(" ")
+				}
+				if name == "br" || name == "img" || name == "hr" {
+					continue
+				}
+			}
+			if face == "jats:p" {
+				// A paragraph can't be inside another element, so the open elements are closed first.
+				for i := len(open) - 1; i >= 0; i-- {
+					if open[i].face != "" {
+						out.WriteString("</" + open[i].face + ">")
+					}
+				}
+				open = open[:0]
+			}
+			open = append(open, struct{ name, face string }{name, face})
+			if face == "" {
+				continue
+			}
+			out.WriteString("<" + face)
+			for _, attr := range t.Attr {
+				if math > 0 && attr.Name.Space == "" && attr.Name.Local != "xmlns" {
+					out.WriteString(" " + attr.Name.Local + "=\"" + escapeXML(attr.Value) + "\"")
+				}
+			}
+			out.WriteString(">")
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].name != name {
+					continue
+				}
+				for len(open) > i {
+					closed := open[len(open)-1]
+					open = open[:len(open)-1]
+					if strings.HasPrefix(closed.face, "mml:") {
+						math--
+					}
+					if closed.face != "" {
+						out.WriteString("</" + closed.face + ">")
+					}
+				}
+				break
+			}
+		case xml.CharData:
+			out.WriteString(escapeXML(whitespace.ReplaceAllString(string(t), " ")))
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		if open[i].face != "" {
+			out.WriteString("</" + open[i].face + ">")
+		}
+	}
+
+	return strings.TrimSpace(whitespace.ReplaceAllString(out.String(), " "))
+}
+
+// entityPattern matches a character entity like "&amp;" or "&#233;".
+var entityPattern = regexp.MustCompile(`^&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// topLevelText reports for each byte of a title whether it is text outside every element, tag and entity.
+func topLevelText(text string) []bool {
+
+	topLevel := make([]bool, len(text))
+	depth := 0
+
+	for i := 0; i < len(text); {
+		if entity := entityPattern.FindString(text[i:]); entity != "" {
+			i += len(entity)
+			continue
+		}
+		if text[i] != '<' || i+1 == len(text) || !(isLetter(text[i+1]) || text[i+1] == '/') {
+			topLevel[i] = depth == 0
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(text[i:], '>')
+		if end < 0 {
+			break
+		}
+		tag := text[i+1 : i+end]
+		i += end + 1
+
+		name := strings.ToLower(strings.TrimPrefix(tag, "/"))
+		if fields := strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '/' }); len(fields) > 0 {
+			name = fields[0]
+		}
+		switch {
+		case strings.HasPrefix(tag, "/"):
+			if depth > 0 {
+				depth--
+			}
+		case strings.HasSuffix(tag, "/") || name == "br" || name == "img" || name == "hr":
+		default:
+			depth++
+		}
+	}
+
+	return topLevel
+}
+
+func isLetter(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
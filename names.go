@@ -0,0 +1,187 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PersonName is a person's name split into the parts used by Crossref.
+type PersonName struct {
+	GivenName string `json:"givenName"`
+	Surname   string `json:"surname"`
+	Suffix    string `json:"suffix"`
+}
+
+// particles are the nobiliary particles which start a surname, like the "van" in "Ludwig van Beethoven".
+var particles = []string{
+	"al", "bin", "binti", "da", "dal", "das", "de", "degli", "dei", "del", "della", "der",
+	"di", "dos", "du", "el", "la", "le", "ten", "ter", "van", "vande", "vander", "von", "zu",
+}
+
+// suffixes are the generational suffixes which end a name, like the "Jr." in "John Smith Jr.".
+var suffixes = []string{"jr", "jr.", "jnr", "jnr.", "sr", "sr.", "snr", "snr.", "ii", "iii", "iv"}
+
+// compoundCJKSurnames are the common two character Chinese surnames.
+var compoundCJKSurnames = []string{
+	"欧阳", "歐陽", "司马", "司馬", "诸葛", "諸葛", "上官", "司徒", "东方", "東方",
+	"皇甫", "尉迟", "尉遲", "公孙", "公孫", "慕容", "令狐", "夏侯", "长孙", "長孫", "宇文",
+}
+
+// ParseName splits a name into a given name, surname and suffix.
+// Names in the overrides table are returned as configured. Otherwise the parser handles
+// comma-inverted names ("Smith, John"), multi-word given names ("Mary Ann Smith"),
+// nobiliary particles ("Ludwig van Beethoven"), generational suffixes ("John Smith Jr.")
+// and CJK names, which are written surname first. Mononymous people only have a surname.
+func ParseName(name string, overrides map[string]PersonName) PersonName {
+
+	name = strings.Join(strings.Fields(name), " ")
+
+	if override, ok := overrides[name]; ok {
+		return override
+	}
+
+	if isCJKName(name) {
+		return parseCJKName(name)
+	}
+
+	parts := strings.Split(name, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	suffix := ""
+	if len(parts) > 1 && isSuffix(parts[len(parts)-1]) {
+		suffix = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	if len(parts) > 1 {
+		// Inverted, like "Smith, John" or "van Beethoven, Ludwig".
+		return PersonName{
+			GivenName: strings.Join(parts[1:], " "),
+			Surname:   parts[0],
+			Suffix:    suffix,
+		}
+	}
+
+	tokens := strings.Fields(parts[0])
+	if suffix == "" && len(tokens) > 1 && isSuffix(tokens[len(tokens)-1]) {
+		suffix = tokens[len(tokens)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	switch len(tokens) {
+	case 0:
+		return PersonName{Suffix: suffix}
+	case 1:
+		return PersonName{Surname: tokens[0], Suffix: suffix}
+	}
+
+	// The surname starts at the first particle after the given name, or is the last word.
+	surnameStart := len(tokens) - 1
+	for i := 1; i < len(tokens)-1; i++ {
+		if contains(particles, strings.ToLower(tokens[i])) {
+			surnameStart = i
+			break
+		}
+	}
+
+	return PersonName{
+		GivenName: strings.Join(tokens[:surnameStart], " "),
+		Surname:   strings.Join(tokens[surnameStart:], " "),
+		Suffix:    suffix,
+	}
+}
+
+func isSuffix(s string) bool {
+	return contains(suffixes, strings.ToLower(s))
+}
+
+// isCJKName reports whether a name is written in Chinese, Japanese or Korean script.
+func isCJKName(name string) bool {
+	for _, r := range name {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCJKName splits a name written surname first. Without a space, a Chinese name of two or three characters is
+// split after its first character, or after its first two for common compound surnames, and a Korean name of two or
+// three syllables after its first syllable. Other unspaced names, like Japanese names, whose surname length can't be
+// told from the characters alone, are kept whole as the surname and can be split with the name overrides.
+func parseCJKName(name string) PersonName {
+
+	tokens := strings.Fields(name)
+	if len(tokens) > 1 {
+		return PersonName{
+			GivenName: strings.Join(tokens[1:], " "),
+			Surname:   tokens[0],
+		}
+	}
+
+	runes := []rune(name)
+	surnameLength := 0
+	switch {
+	case allInScript(runes, unicode.Hangul):
+		if len(runes) == 2 || len(runes) == 3 {
+			surnameLength = 1
+		}
+	case allInScript(runes, unicode.Han):
+		for _, compound := range compoundCJKSurnames {
+			if strings.HasPrefix(name, compound) && (len(runes) == 3 || len(runes) == 4) {
+				surnameLength = 2
+				break
+			}
+		}
+		if surnameLength == 0 && (len(runes) == 2 || len(runes) == 3) {
+			surnameLength = 1
+		}
+	}
+
+	if surnameLength == 0 {
+		return PersonName{Surname: name}
+	}
+
+	return PersonName{
+		GivenName: string(runes[surnameLength:]),
+		Surname:   string(runes[:surnameLength]),
+	}
+}
+
+// allInScript reports whether every character of a name is in a script.
+func allInScript(runes []rune, script *unicode.RangeTable) bool {
+	for _, r := range runes {
+		if !unicode.Is(script, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// diacritics maps accented Latin letters to their unaccented forms.
+var diacritics = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ă", "a", "ą", "a",
+	"ç", "c", "ć", "c", "č", "c", "ď", "d", "đ", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ė", "e", "ę", "e", "ě", "e",
+	"ğ", "g", "ì", "i", "í", "i", "î", "i", "ï", "i", "ī", "i", "į", "i", "ı", "i",
+	"ł", "l", "ľ", "l", "ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ō", "o", "ő", "o",
+	"ŕ", "r", "ř", "r", "ś", "s", "š", "s", "ş", "s", "ș", "s", "ß", "ss",
+	"ť", "t", "ţ", "t", "ț", "t", "ù", "u", "ú", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u", "ų", "u",
+	"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z", "æ", "ae", "œ", "oe", "þ", "th", "ð", "d",
+)
+
+// normalizeName lowercases a person's or organization's name, removes accents, and replaces punctuation and runs of
+// whitespace with single spaces, so names can be compared.
+func normalizeName(name string) string {
+	return strings.Join(strings.FieldsFunc(foldDiacritics(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// foldDiacritics lowercases a string and removes the accents from Latin letters.
+func foldDiacritics(s string) string {
+	return diacritics.Replace(strings.ToLower(s))
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestParseName(t *testing.T) {
+
+	overrides := map[string]PersonName{
+		"Maria de la Cruz Lopez": {GivenName: "Maria", Surname: "de la Cruz Lopez"},
+	}
+
+	tests := []struct {
+		name string
+		want PersonName
+	}{
+		{"Ada Lovelace", PersonName{GivenName: "Ada", Surname: "Lovelace"}},
+		{"Smith, John", PersonName{GivenName: "John", Surname: "Smith"}},
+		{"van Beethoven, Ludwig", PersonName{GivenName: "Ludwig", Surname: "van Beethoven"}},
+		{"Mary Ann Smith", PersonName{GivenName: "Mary Ann", Surname: "Smith"}},
+		{"Ludwig van Beethoven", PersonName{GivenName: "Ludwig", Surname: "van Beethoven"}},
+		{"John Smith Jr.", PersonName{GivenName: "John", Surname: "Smith", Suffix: "Jr."}},
+		{"Smith, John, III", PersonName{GivenName: "John", Surname: "Smith", Suffix: "III"}},
+		{"Plato", PersonName{Surname: "Plato"}},
+		{"王小明", PersonName{GivenName: "小明", Surname: "王"}},
+		{"欧阳明华", PersonName{GivenName: "明华", Surname: "欧阳"}},
+		{"김민수", PersonName{GivenName: "민수", Surname: "김"}},
+		{"山田 太郎", PersonName{GivenName: "太郎", Surname: "山田"}},
+		{"山田太郎", PersonName{Surname: "山田太郎"}},
+		{"田中さくら", PersonName{Surname: "田中さくら"}},
+		{"  Maria  de la Cruz Lopez ", PersonName{GivenName: "Maria", Surname: "de la Cruz Lopez"}},
+		{"", PersonName{}},
+		{"   ", PersonName{}},
+		{", Jr.", PersonName{Suffix: "Jr."}},
+	}
+
+	for _, test := range tests {
+		if got := ParseName(test.name, overrides); got != test.want {
+			t.Errorf("ParseName(%q) = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
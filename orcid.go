@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// orcidPattern matches an ORCID as a bare identifier or a URL, with or without hyphens.
+var orcidPattern = regexp.MustCompile(`(?i)^(?:(?:https?://)?(?:www\.)?orcid\.org/)?(\d{4})-?(\d{4})-?(\d{4})-?(\d{3}[\dx])$`)
+
+// orcidInNamePattern matches an ORCID written in an author's name field, like "Ada Lovelace (ORCID: 0000-0002-1825-0097)".
+var orcidInNamePattern = regexp.MustCompile(`(?i)[\s,;]*[(\[]?\s*(?:orcid(?:\s*id)?\s*:?\s*)?((?:https?://)?(?:www\.)?(?:orcid\.org/)?\d{4}-\d{4}-\d{4}-\d{3}[\dx])\s*[)\]]?`)
+
+// ORCIDIndex holds the ORCIDs from the config file, keyed by email address, by normalized name,
+// and by surname and first initial, and which of them were collected through an authenticated workflow.
+type ORCIDIndex struct {
+	emails        map[string][]string
+	names         map[string][]string
+	initials      map[string][]initialsEntry
+	authenticated map[string]bool
+}
+
+// initialsEntry is an ORCID with the normalized given name it was configured with.
+type initialsEntry struct {
+	givenName string
+	orcid     string
+}
+
+// NewORCIDIndex returns an empty ORCID index.
+func NewORCIDIndex() *ORCIDIndex {
+	return &ORCIDIndex{
+		emails:        make(map[string][]string),
+		names:         make(map[string][]string),
+		initials:      make(map[string][]initialsEntry),
+		authenticated: make(map[string]bool),
+	}
+}
+
+// Add adds a normalized ORCID for a person's name, email address, or both. The name is split with the name overrides.
+func (index *ORCIDIndex) Add(name, email, orcid string, authenticated bool, overrides map[string]PersonName) {
+
+	if authenticated {
+		index.authenticated[orcid] = true
+	}
+
+	if email = normalizeEmail(email); email != "" {
+		index.emails[email] = appendUnique(index.emails[email], orcid)
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return
+	}
+
+	if key := normalizeName(name); key != "" {
+		index.names[key] = appendUnique(index.names[key], orcid)
+	}
+	personName := ParseName(name, overrides)
+	if key := initialsKey(personName); key != "" {
+		index.initials[key] = append(index.initials[key], initialsEntry{normalizeName(personName.GivenName), orcid})
+	}
+}
+
+// Match returns the ORCID for a person, trying their email address, then their normalized name,
+// then their surname and first initial. An error is returned if more than one ORCID matches, or if the
+// given names only share their first initial.
+func (index *ORCIDIndex) Match(name, email string, overrides map[string]PersonName) (string, error) {
+
+	candidates := [][]string{
+		index.emails[normalizeEmail(email)],
+		index.names[normalizeName(name)],
+	}
+	for _, orcids := range candidates {
+		if len(orcids) > 0 {
+			return onlyORCID(name, orcids)
+		}
+	}
+
+	orcids, err := index.matchInitials(name, ParseName(name, overrides))
+	if err != nil || len(orcids) == 0 {
+		return "", err
+	}
+
+	return onlyORCID(name, orcids)
+}
+
+// onlyORCID returns the ORCID a person matches, or an error if they match more than one.
+func onlyORCID(name string, orcids []string) (string, error) {
+	if len(orcids) > 1 {
+		return "", fmt.Errorf("\"%v\" matches more than one ORCID: %v", strings.TrimSpace(name), strings.Join(orcids, ", "))
+	}
+	return orcids[0], nil
+}
+
+// matchInitials returns the ORCIDs whose surname and given name match a person's when one given name is an initial
+// or a prefix of the other, so "A. Lovelace" matches "Ada Lovelace". An error is returned if the surname and first
+// initial match but the given names don't, like "Alan Lovelace" and "Ada Lovelace".
+func (index *ORCIDIndex) matchInitials(name string, personName PersonName) ([]string, error) {
+
+	entries := index.initials[initialsKey(personName)]
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	givenName := normalizeName(personName.GivenName)
+	orcids := []string{}
+	for _, entry := range entries {
+		if isInitialOrPrefix(givenName, entry.givenName) || isInitialOrPrefix(entry.givenName, givenName) {
+			orcids = appendUnique(orcids, entry.orcid)
+		}
+	}
+	if len(orcids) == 0 {
+		return nil, fmt.Errorf("\"%v\" only matches an ORCID by surname and first initial, with a different given name", strings.TrimSpace(name))
+	}
+
+	return orcids, nil
+}
+
+// isInitialOrPrefix reports whether a normalized given name is an initial, or the first words of another given name.
+func isInitialOrPrefix(givenName, other string) bool {
+	return len([]rune(strings.Fields(givenName)[0])) == 1 || other == givenName || strings.HasPrefix(other, givenName+" ")
+}
+
+// ORCID returns the ORCID URL for a person, and whether it was collected through an authenticated workflow.
+// An ORCID from the input record is used before one matched in the config file. Only ORCIDs marked as
+// authenticated in the config file are authenticated.
+func (m *Mappings) ORCID(name, email, inputORCID string) (string, bool, error) {
+
+	orcid := ""
+	var err error
+
+	if strings.TrimSpace(inputORCID) != "" {
+		orcid, err = NormalizeORCID(inputORCID)
+	} else {
+		orcid, err = m.ORCIDs.Match(name, email, m.Names)
+	}
+	if orcid == "" {
+		return "", false, err
+	}
+
+	return orcid, m.ORCIDs.authenticated[orcid], nil
+}
+
+// NormalizeORCID returns an ORCID as an https://orcid.org/ URL. Bare identifiers and http, www and
+// unhyphenated forms are accepted. An error is returned if the ORCID is malformed or its check digit is wrong.
+func NormalizeORCID(orcid string) (string, error) {
+
+	parts := orcidPattern.FindStringSubmatch(strings.TrimSpace(orcid))
+	if parts == nil {
+		return "", fmt.Errorf("\"%v\" is not a valid ORCID", orcid)
+	}
+
+	identifier := strings.ToUpper(strings.Join(parts[1:], "-"))
+	if !validORCIDCheckDigit(strings.Replace(identifier, "-", "", -1)) {
+		return "", fmt.Errorf("ORCID \"%v\" has an invalid check digit", orcid)
+	}
+
+	return "https://orcid.org/" + identifier, nil
+}
+
+// validORCIDCheckDigit reports whether the last character of a 16 character ORCID is its ISO 7064 11,2 check digit.
+func validORCIDCheckDigit(digits string) bool {
+
+	total := 0
+	for _, r := range digits[:15] {
+		total = (total + int(r-'0')) * 2
+	}
+
+	check := (12 - total%11) % 11
+	if check == 10 {
+		return digits[15:] == "X"
+	}
+
+	return digits[15:] == strconv.Itoa(check)
+}
+
+// SplitORCID removes an ORCID from an author's name field, returning the name and the ORCID as written.
+func SplitORCID(name string) (string, string) {
+
+	match := orcidInNamePattern.FindStringSubmatchIndex(name)
+	if match == nil {
+		return name, ""
+	}
+
+	return strings.TrimSpace(name[:match[0]] + " " + name[match[1]:]), name[match[2]:match[3]]
+}
+
+// initialsKey returns a person's normalized surname and the first letter of their given name, so "A. Lovelace" matches "Ada Lovelace".
+// People without a given name have no key.
+func initialsKey(personName PersonName) string {
+
+	surname := normalizeName(personName.Surname)
+	givenName := []rune(normalizeName(personName.GivenName))
+	if surname == "" || len(givenName) == 0 {
+		return ""
+	}
+
+	return surname + " " + string(givenName[0])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
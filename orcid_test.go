@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestMatchInitials(t *testing.T) {
+
+	index := NewORCIDIndex()
+	index.Add("Ada Lovelace", "", "https://orcid.org/0000-0002-1825-0097", true, nil)
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"Ada Lovelace", "https://orcid.org/0000-0002-1825-0097", false},
+		{"A. Lovelace", "https://orcid.org/0000-0002-1825-0097", false},
+		{"Lovelace, Ada King", "https://orcid.org/0000-0002-1825-0097", false},
+		{"Alan Lovelace", "", true},
+		{"Adam Lovelace", "", true},
+		{"Charles Babbage", "", false},
+	}
+
+	for _, test := range tests {
+		got, err := index.Match(test.name, "", nil)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("Match(%q) = %q, %v, want %q and error %v", test.name, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestORCIDAlanIsNotAda(t *testing.T) {
+
+	mappings := &Mappings{ORCIDs: NewORCIDIndex()}
+	mappings.ORCIDs.Add("Ada Lovelace", "", "https://orcid.org/0000-0002-1825-0097", true, nil)
+
+	orcid, authenticated, err := mappings.ORCID("Alan Lovelace", "", "")
+	if orcid != "" || authenticated || err == nil {
+		t.Errorf("ORCID(\"Alan Lovelace\") = %q, %v, %v, want no ORCID and an error", orcid, authenticated, err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pagePolicies are the ways a journal's articles without a start page can be deposited: with a first page of 1 (the default),
+// without pages, or with an article number taken from the publisherRecordId or the last part of the landing page URL.
+var pagePolicies = []string{"default", "omit", "recordId", "url"}
+
+// romanNumerals are the values of the roman numerals used in front matter page numbers.
+var romanNumerals = map[rune]int{'i': 1, 'v': 5, 'x': 10, 'l': 50, 'c': 100, 'd': 500, 'm': 1000}
+
+// Pages holds an article's first and last pages, and the other pages of an article which isn't on contiguous pages.
+type Pages struct {
+	FirstPage  string
+	LastPage   string
+	OtherPages string
+}
+
+// CreatePages returns a record's pages. The start page may hold a discontinuous list of ranges, like "1-3, 7-9, 11",
+// in which case the first range gives the first and last pages and the rest are other pages. A range is only split when
+// both its pages are arabic numbers or both are roman numerals, so pages like "S-12" and "e-123" are kept whole, and
+// the record's end page is used before the end of the range. Roman numeral and alphanumeric pages are kept as they are.
+func CreatePages(record *DOAJRecord) Pages {
+
+	pages := Pages{}
+	if record.DOAJStartPage != nil {
+		pages.FirstPage = strings.TrimSpace(record.DOAJStartPage.Text)
+	}
+	if record.DOAJEndPage != nil {
+		pages.LastPage = strings.TrimSpace(record.DOAJEndPage.Text)
+	}
+
+	ranges := strings.Split(pages.FirstPage, ",")
+	if len(ranges) > 1 {
+		others := []string{}
+		for _, r := range ranges[1:] {
+			if r = strings.TrimSpace(r); r != "" {
+				others = append(others, r)
+			}
+		}
+		pages.OtherPages = strings.Join(others, ", ")
+	}
+
+	pages.FirstPage = strings.TrimSpace(ranges[0])
+	first := strings.SplitN(pages.FirstPage, "-", 2)
+	if len(first) > 1 && isPageRange(strings.TrimSpace(first[0]), strings.TrimSpace(first[1])) {
+		pages.FirstPage = strings.TrimSpace(first[0])
+		if pages.LastPage == "" {
+			pages.LastPage = strings.TrimSpace(first[1])
+		}
+	}
+
+	return pages
+}
+
+// ItemNumber returns a record's article number under a journal's page policy, or an empty string if the policy doesn't use one.
+func ItemNumber(pagePolicy string, record *DOAJRecord) string {
+
+	switch pagePolicy {
+	case "recordId":
+		if record.DOAJPublisherRecordID != nil && record.DOAJPublisherRecordID.Text != 0 {
+			return strconv.FormatInt(record.DOAJPublisherRecordID.Text, 10)
+		}
+	case "url":
+		landingPageURL, err := url.Parse(record.LandingPageURL())
+		if err == nil && strings.Trim(landingPageURL.Path, "/") != "" {
+			return path.Base(strings.TrimRight(landingPageURL.Path, "/"))
+		}
+	}
+
+	return ""
+}
+
+// comparePages compares two page numbers, returning -1, 0 or 1, and whether they could be compared. Arabic numbers,
+// roman numerals, and numbers with the same letter prefix, like "e12" and "e15", can be compared.
+func comparePages(a, b string) (int, bool) {
+
+	aPrefix, aNumber, aOK := pageNumber(a)
+	bPrefix, bNumber, bOK := pageNumber(b)
+	if !aOK || !bOK || !strings.EqualFold(aPrefix, bPrefix) {
+		return 0, false
+	}
+
+	switch {
+	case aNumber < bNumber:
+		return -1, true
+	case aNumber > bNumber:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// pageNumber splits a page into its letter prefix and number. Roman numerals are returned with the prefix "roman".
+func pageNumber(page string) (string, int, bool) {
+
+	page = strings.TrimSpace(page)
+
+	if number, ok := romanToInt(page); ok {
+		return "roman", number, true
+	}
+
+	digits := strings.IndexFunc(page, unicode.IsDigit)
+	if digits < 0 {
+		return "", 0, false
+	}
+
+	number, err := strconv.Atoi(page[digits:])
+	if err != nil {
+		return "", 0, false
+	}
+
+	return page[:digits], number, true
+}
+
+// isPageRange reports whether two pages are the ends of a range, which they are if both are arabic numbers or both are roman numerals.
+func isPageRange(first, last string) bool {
+
+	if _, err := strconv.Atoi(first); err == nil {
+		_, err = strconv.Atoi(last)
+		return err == nil
+	}
+
+	_, firstOK := romanToInt(first)
+	_, lastOK := romanToInt(last)
+	return firstOK && lastOK
+}
+
+func romanToInt(s string) (int, bool) {
+
+	if s == "" {
+		return 0, false
+	}
+
+	total := 0
+	previous := 0
+	runes := []rune(strings.ToLower(s))
+	for i := len(runes) - 1; i >= 0; i-- {
+		value, ok := romanNumerals[runes[i]]
+		if !ok {
+			return 0, false
+		}
+		if value < previous {
+			total -= value
+		} else {
+			total += value
+			previous = value
+		}
+	}
+
+	return total, true
+}
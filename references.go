@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Citation is one entry in an article's reference list.
+type Citation struct {
+	Key          string `json:"key"`
+	ISSN         string `json:"issn"`
+	JournalTitle string `json:"journalTitle"`
+	Author       string `json:"author"`
+	Volume       string `json:"volume"`
+	Issue        string `json:"issue"`
+	FirstPage    string `json:"firstPage"`
+	Year         string `json:"year"`
+	DOI          string `json:"doi"`
+	ArticleTitle string `json:"articleTitle"`
+	Unstructured string `json:"unstructured"`
+}
+
+// LoadReferences loads reference lists keyed by article URL.
+// The path is either a JSON file mapping each full text URL to a list of citations,
+// or a directory of plain-text files with one reference per line.
+// Files in a directory are named after the last element of the full text URL's path, plus ".txt".
+func LoadReferences(referencesPath string) (map[string][]Citation, error) {
+
+	references := make(map[string][]Citation)
+
+	absoluteReferencesPath, err := filepath.Abs(referencesPath)
+	if err != nil {
+		return references, err
+	}
+
+	info, err := os.Stat(absoluteReferencesPath)
+	if err != nil {
+		return references, err
+	}
+
+	if info.IsDir() {
+		return loadReferencesDirectory(absoluteReferencesPath)
+	}
+
+	referencesFile, err := os.Open(absoluteReferencesPath)
+	if err != nil {
+		return references, err
+	}
+	defer referencesFile.Close()
+
+	referencesDecoder := json.NewDecoder(referencesFile)
+	err = referencesDecoder.Decode(&references)
+	if err != nil {
+		return references, err
+	}
+
+	// Check if any citation key is used twice in an article's reference list.
+	for uri, citations := range references {
+		keys := make(map[string]bool)
+		for _, citation := range citations {
+			key := strings.TrimSpace(citation.Key)
+			if key == "" {
+				continue
+			}
+			if keys[key] {
+				return references, fmt.Errorf("citation key \"%v\" is used more than once for \"%v\"", key, uri)
+			}
+			keys[key] = true
+		}
+	}
+
+	return references, nil
+}
+
+func loadReferencesDirectory(directoryPath string) (map[string][]Citation, error) {
+
+	references := make(map[string][]Citation)
+
+	files, err := ioutil.ReadDir(directoryPath)
+	if err != nil {
+		return references, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".txt" {
+			continue
+		}
+
+		citations, err := loadReferencesFile(filepath.Join(directoryPath, file.Name()))
+		if err != nil {
+			return references, err
+		}
+
+		references[strings.TrimSuffix(file.Name(), ".txt")] = citations
+	}
+
+	return references, nil
+}
+
+func loadReferencesFile(filePath string) ([]Citation, error) {
+
+	citations := []Citation{}
+
+	referencesFile, err := os.Open(filePath)
+	if err != nil {
+		return citations, err
+	}
+	defer referencesFile.Close()
+
+	scanner := bufio.NewScanner(referencesFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		citations = append(citations, Citation{Unstructured: line})
+	}
+
+	return citations, scanner.Err()
+}
+
+// CreateCitations returns the escaped reference list for an article.
+// Citations without a key are given one derived from their content, so keys don't change when other references
+// are added or removed. Identical citations are told apart with a numeric suffix.
+func CreateCitations(citations []Citation) []Citation {
+
+	created := []Citation{}
+	keys := make(map[string]bool)
+	for _, citation := range citations {
+		keys[strings.TrimSpace(citation.Key)] = true
+	}
+
+	for _, citation := range citations {
+		c := Citation{
+			Key:          strings.TrimSpace(citation.Key),
+			ISSN:         escapeXML(strings.TrimSpace(citation.ISSN)),
+			JournalTitle: escapeXML(strings.TrimSpace(citation.JournalTitle)),
+			Author:       escapeXML(strings.TrimSpace(citation.Author)),
+			Volume:       escapeXML(strings.TrimSpace(citation.Volume)),
+			Issue:        escapeXML(strings.TrimSpace(citation.Issue)),
+			FirstPage:    escapeXML(strings.TrimSpace(citation.FirstPage)),
+			Year:         escapeXML(strings.TrimSpace(citation.Year)),
+			DOI:          escapeXML(NormalizeDOI(citation.DOI)),
+			ArticleTitle: escapeXML(strings.TrimSpace(citation.ArticleTitle)),
+			Unstructured: escapeXML(strings.TrimSpace(citation.Unstructured)),
+		}
+		if c.Key == "" {
+			key := citationKey(citation)
+			c.Key = key
+			for i := 2; keys[c.Key]; i++ {
+				c.Key = key + "-" + strconv.Itoa(i)
+			}
+			keys[c.Key] = true
+		}
+		c.Key = escapeXML(c.Key)
+
+		created = append(created, c)
+	}
+
+	return created
+}
+
+// citationKey returns a key made from a hash of a citation's normalized content.
+func citationKey(citation Citation) string {
+
+	fields := []string{
+		NormalizeDOI(citation.DOI), citation.Author, citation.Year, citation.ArticleTitle, citation.JournalTitle,
+		citation.ISSN, citation.Volume, citation.Issue, citation.FirstPage, citation.Unstructured,
+	}
+	for i, field := range fields {
+		fields[i] = strings.ToLower(strings.Join(strings.Fields(field), " "))
+	}
+
+	hash := sha1.Sum([]byte(strings.Join(fields, "|")))
+	return "ref-" + hex.EncodeToString(hash[:])[:10]
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Relation is a Crossref relation from an article to another work.
+type Relation struct {
+	Element        string
+	Type           string
+	IdentifierType string
+	Identifier     string
+}
+
+// intraWorkRelationTypes relate different forms of the same work.
+var intraWorkRelationTypes = []string{
+	"isExpressionOf", "hasExpression", "isManifestationOf", "hasManifestation",
+	"isManuscriptOf", "hasManuscript", "isPreprintOf", "hasPreprint",
+	"isReplacedBy", "replaces", "isTranslationOf", "hasTranslation",
+	"isVariantFormOf", "isOriginalFormOf", "isVersionOf", "hasVersion",
+	"isSameAs", "isIdenticalTo",
+}
+
+// interWorkRelationTypes relate different works.
+var interWorkRelationTypes = []string{
+	"isDerivedFrom", "hasDerivation", "isReviewOf", "hasReview",
+	"isCommentOn", "hasComment", "isReplyTo", "hasReply",
+	"basedOnData", "isDataBasisFor", "hasRelatedMaterial", "isRelatedMaterial",
+	"isCompiledBy", "compiles", "isDocumentedBy", "documents",
+	"isSupplementTo", "isSupplementedBy", "isContinuedBy", "continues",
+	"isPartOf", "hasPart", "references", "isReferencedBy",
+	"isBasedOn", "isBasisFor", "requires", "isRequiredBy",
+	"finances", "isFinancedBy",
+}
+
+var doiPattern = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+
+// NewRelation returns a relation of the given type to a DOI or URI.
+// An error is returned if the relation type is unknown or the identifier is neither a DOI nor a URI.
+func NewRelation(relationType, identifier string) (Relation, error) {
+
+	relation := Relation{Type: strings.TrimSpace(relationType)}
+
+	switch {
+	case contains(intraWorkRelationTypes, relation.Type):
+		relation.Element = "intra_work_relation"
+	case contains(interWorkRelationTypes, relation.Type):
+		relation.Element = "inter_work_relation"
+	default:
+		return relation, fmt.Errorf("unknown relation type \"%v\"", relationType)
+	}
+
+	doi := NormalizeDOI(identifier)
+	if doiPattern.MatchString(doi) {
+		relation.IdentifierType = "doi"
+		relation.Identifier = escapeXML(doi)
+		return relation, nil
+	}
+
+	uri, err := url.Parse(strings.TrimSpace(identifier))
+	if err == nil && (uri.Scheme == "http" || uri.Scheme == "https") && uri.Host != "" {
+		relation.IdentifierType = "uri"
+		relation.Identifier = escapeXML(uri.String())
+		return relation, nil
+	}
+
+	return relation, fmt.Errorf("relation target \"%v\" is not a DOI or URI", identifier)
+}
+
+// LoadRelations loads relations from a csv file, keyed by article URL.
+// The file has a header row, followed by rows of the article's URL, the relation type, and the DOI or URI of the related work.
+func LoadRelations(relationsFilePath string) (map[string][]Relation, error) {
+
+	relations := make(map[string][]Relation)
+
+	rows, err := readCSVRows(relationsFilePath, 3)
+	if err != nil {
+		return relations, err
+	}
+
+	for _, row := range rows {
+		uri := strings.TrimSpace(row[0])
+		relation, err := NewRelation(row[1], row[2])
+		if err != nil {
+			return relations, fmt.Errorf("relation for \"%v\": %v", uri, err)
+		}
+
+		relations[uri] = append(relations[uri], relation)
+	}
+
+	return relations, nil
+}
+
+// PairTranslations returns translation relations between records which share a publisher record ID but not a language, keyed by article URL.
+// The first record in a group is the original, each later record is a translation of it. Records without a publisher record ID aren't paired.
+func PairTranslations(mappings *Mappings, records *DOAJRecords) map[string][]Relation {
+
+	relations := make(map[string][]Relation)
+	groups := make(map[string][]*DOAJRecord)
+	order := []string{}
+
+	for _, record := range records.DOAJRecords {
+		if record.DOAJPublisherRecordID == nil || record.DOAJPublisherRecordID.Text == 0 {
+			continue
+		}
+		id := strconv.FormatInt(record.DOAJPublisherRecordID.Text, 10)
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], record)
+	}
+
+	for _, id := range order {
+		original := groups[id][0]
+		originalDOI := escapeXML(CreateDOI(mappings, original))
+
+		for _, translation := range groups[id][1:] {
+			if translation.LanguageCode() == original.LanguageCode() {
+				continue
+			}
+			translationDOI := escapeXML(CreateDOI(mappings, translation))
+
+			relations[translation.LandingPageURL()] = append(relations[translation.LandingPageURL()],
+				Relation{"intra_work_relation", "isTranslationOf", "doi", originalDOI})
+			relations[original.LandingPageURL()] = append(relations[original.LandingPageURL()],
+				Relation{"intra_work_relation", "hasTranslation", "doi", translationDOI})
+		}
+	}
+
+	return relations
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// contributorRoles are the values Crossref accepts for the contributor_role attribute.
+var contributorRoles = []string{
+	"author", "editor", "chair", "reviewer", "review-assistant",
+	"stats-reviewer", "reviewer-external", "reader", "translator",
+}
+
+// roleSuffixes maps the role suffixes used in name fields, like the "(trans.)" in "Ada Lovelace (trans.)", to contributor roles.
+var roleSuffixes = map[string]string{
+	"trans.":     "translator",
+	"trans":      "translator",
+	"tr.":        "translator",
+	"translator": "translator",
+	"ed.":        "editor",
+	"ed":         "editor",
+	"eds.":       "editor",
+	"editor":     "editor",
+	"rev.":       "reviewer",
+	"reviewer":   "reviewer",
+	"chair":      "chair",
+	"reader":     "reader",
+}
+
+var roleSuffixPattern = regexp.MustCompile(`\s*\(([^()]+)\)\s*$`)
+
+// SplitRole splits a role suffix from a name. Names without a known role suffix are authors.
+func SplitRole(name string) (string, string) {
+
+	name = strings.TrimSpace(name)
+
+	match := roleSuffixPattern.FindStringSubmatchIndex(name)
+	if match != nil {
+		if role, ok := roleSuffixes[strings.ToLower(strings.TrimSpace(name[match[2]:match[3]]))]; ok {
+			return strings.TrimSpace(name[:match[0]]), role
+		}
+	}
+
+	return name, "author"
+}
+
+// LoadRoles loads contributor role overrides from a csv file, keyed by article URL and then by contributor name.
+// The file has a header row, followed by rows of the article's URL, the contributor's name, and their Crossref contributor role.
+func LoadRoles(rolesFilePath string) (map[string]map[string]string, error) {
+
+	roles := make(map[string]map[string]string)
+
+	rows, err := readCSVRows(rolesFilePath, 3)
+	if err != nil {
+		return roles, err
+	}
+
+	for _, row := range rows {
+		uri := strings.TrimSpace(row[0])
+		role := strings.ToLower(strings.TrimSpace(row[2]))
+		if !contains(contributorRoles, role) {
+			return roles, fmt.Errorf("role for \"%v\" in \"%v\" is invalid: \"%v\"", row[1], uri, row[2])
+		}
+
+		if roles[uri] == nil {
+			roles[uri] = make(map[string]string)
+		}
+		name, _ := SplitRole(row[1])
+		roles[uri][normalizeRoleName(name)] = role
+	}
+
+	return roles, nil
+}
+
+func normalizeRoleName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
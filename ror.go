@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// rorMatchThreshold is the lowest word overlap between an affiliation and a ROR organization name that counts as a fuzzy match.
+const rorMatchThreshold = 0.6
+
+// RORIndex holds organization names from a ROR data dump, for matching affiliations to ROR IDs.
+type RORIndex struct {
+	exact map[string]string
+	names []rorName
+	cache map[string]string
+}
+
+type rorName struct {
+	words map[string]bool
+	id    string
+}
+
+// rorOrganization holds the fields of a ROR record used for matching. Both the version 1 and version 2 schemas are read.
+type rorOrganization struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Acronyms []string `json:"acronyms"`
+	Labels   []struct {
+		Label string `json:"label"`
+	} `json:"labels"`
+	Names []struct {
+		Value string `json:"value"`
+	} `json:"names"`
+}
+
+// LoadRORDump loads a ROR data dump JSON file into an index.
+func LoadRORDump(dumpFilePath string) (*RORIndex, error) {
+
+	index := &RORIndex{
+		exact: make(map[string]string),
+		cache: make(map[string]string),
+	}
+	organizations := []rorOrganization{}
+
+	absoluteDumpFilePath, err := filepath.Abs(dumpFilePath)
+	if err != nil {
+		return index, err
+	}
+
+	dumpFile, err := os.Open(absoluteDumpFilePath)
+	if err != nil {
+		return index, err
+	}
+	defer dumpFile.Close()
+
+	dumpDecoder := json.NewDecoder(dumpFile)
+	err = dumpDecoder.Decode(&organizations)
+	if err != nil {
+		return index, err
+	}
+
+	for _, organization := range organizations {
+		names := append([]string{organization.Name}, organization.Aliases...)
+		names = append(names, organization.Acronyms...)
+		for _, label := range organization.Labels {
+			names = append(names, label.Label)
+		}
+		for _, name := range organization.Names {
+			names = append(names, name.Value)
+		}
+
+		for _, name := range names {
+			normalized := normalizeName(name)
+			if normalized == "" {
+				continue
+			}
+			// A name shared by several organizations can't be matched exactly.
+			if id, ok := index.exact[normalized]; ok && id != organization.ID {
+				index.exact[normalized] = ""
+			} else {
+				index.exact[normalized] = organization.ID
+			}
+			index.names = append(index.names, rorName{words(normalized), organization.ID})
+		}
+	}
+
+	return index, nil
+}
+
+// Match returns the ROR ID of the organization an affiliation names, or an empty string if there is no unambiguous match.
+// Names are matched exactly first, ignoring case, accents and punctuation, and then by their overlap of words.
+func (index *RORIndex) Match(affiliation string) string {
+
+	normalized := normalizeName(affiliation)
+
+	if id, ok := index.cache[normalized]; ok {
+		return id
+	}
+
+	id, ok := index.exact[normalized]
+	if !ok {
+		id = index.fuzzyMatch(words(normalized))
+	}
+
+	index.cache[normalized] = id
+	return id
+}
+
+func (index *RORIndex) fuzzyMatch(affiliationWords map[string]bool) string {
+
+	bestID := ""
+	bestScore := 0.0
+
+	for _, name := range index.names {
+		score := similarity(affiliationWords, name.words)
+		if score < rorMatchThreshold {
+			continue
+		}
+		if score > bestScore {
+			bestID, bestScore = name.id, score
+		} else if score == bestScore && name.id != bestID {
+			// Equally good matches to different organizations are ambiguous.
+			bestID = ""
+		}
+	}
+
+	return bestID
+}
+
+// similarity returns the number of words two names share, divided by the number of distinct words in both.
+func similarity(a, b map[string]bool) float64 {
+
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func words(normalized string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(normalized) {
+		if word != "the" && word != "of" && word != "and" {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// NormalizeROR returns a ROR ID in its https://ror.org/ URL form.
+func NormalizeROR(ror string) string {
+	ror = strings.TrimSpace(ror)
+	for _, prefix := range []string{"https://ror.org/", "http://ror.org/", "ror.org/"} {
+		if strings.HasPrefix(strings.ToLower(ror), prefix) {
+			ror = ror[len(prefix):]
+			break
+		}
+	}
+	if ror == "" {
+		return ""
+	}
+	return "https://ror.org/" + ror
+}
+
+// ROR returns the ROR ID for an affiliation, from the config file's affiliations or else from the ROR data dump.
+func (m *Mappings) ROR(affiliation string) string {
+
+	if ror, ok := m.RORs[normalizeName(affiliation)]; ok {
+		return ror
+	}
+
+	if m.RORIndex != nil {
+		return m.RORIndex.Match(affiliation)
+	}
+
+	return ""
+}
+
+// UnmatchedAffiliations returns the sorted affiliation names which were not matched to a ROR ID.
+func UnmatchedAffiliations(templateData *TemplateData) []string {
+
+	unmatched := make(map[string]bool)
+
+	for _, journal := range templateData.Journals {
+		for _, article := range journal.Articles {
+			for _, contributor := range article.Contributors {
+				for _, affiliation := range contributor.Affiliations {
+					if affiliation.ROR == "" {
+						unmatched[affiliation.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range unmatched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
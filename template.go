@@ -1,10 +1,111 @@
 package main
 
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// SchemaVersions are the supported Crossref deposit schema versions.
+var SchemaVersions = []string{"4.4.1", "5.3.1"}
+
+// TemplateFuncs returns the functions used by the templates when writing a deposit in a schema version.
+func TemplateFuncs(schemaVersion string) template.FuncMap {
+	return template.FuncMap{
+		// institutions reports whether affiliations are written as institution elements, which were added in schema 5.
+		"institutions": func() bool {
+			return strings.HasPrefix(schemaVersion, "5.")
+		},
+	}
+}
+
+// staticXMLNamespaces are the namespaces declared in the deposit, by prefix, which static XML from the config file may use.
+var staticXMLNamespaces = map[string]string{
+	"ai":   "http://www.crossref.org/AccessIndicators.xsd",
+	"fr":   "http://www.crossref.org/fundref.xsd",
+	"jats": "http://www.ncbi.nlm.nih.gov/JATS1",
+	"mml":  "http://www.w3.org/1998/Math/MathML",
+	"rel":  "http://www.crossref.org/relations.xsd",
+}
+
+// staticXMLElements are the elements, by schema version, which static XML from the config file may hold in each
+// journal_article, between crossmark and the relations' rel:program, in schema order.
+var staticXMLElements = map[string][]string{
+	"4.4.1": {"fr:program", "ai:program"},
+	"5.3.1": {"fr:program", "ai:program"},
+}
+
+// ValidateStaticXML returns an error if static XML from the config file isn't well-formed, uses a namespace prefix
+// which isn't declared in the deposit, or has an element which the schema version doesn't allow in its place, in
+// schema order and at most once. Unprefixed elements are in the Crossref schema's namespace.
+func ValidateStaticXML(staticXML, schemaVersion string) error {
+
+	root := `<root xmlns="http://www.crossref.org/schema"`
+	prefixes := make(map[string]string)
+	for prefix, namespace := range staticXMLNamespaces {
+		root += ` xmlns:` + prefix + `="` + namespace + `"`
+		prefixes[namespace] = prefix + ":"
+	}
+
+	allowed := staticXMLElements[schemaVersion]
+	depth := 0
+
+	d := xml.NewDecoder(strings.NewReader(root + ">" + staticXML + "</root>"))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !strings.Contains(t.Name.Space, "/") {
+				return fmt.Errorf("undeclared namespace prefix \"%v\" on element %v", t.Name.Space, t.Name.Local)
+			}
+			depth++
+			if depth != 2 {
+				continue
+			}
+			name := prefixes[t.Name.Space] + t.Name.Local
+			i := indexOf(allowed, name)
+			if i < 0 {
+				return fmt.Errorf("element %v is not allowed, repeated or out of order; schema %v allows %v, in that order", name, schemaVersion, strings.Join(staticXMLElements[schemaVersion], " and "))
+			}
+			allowed = allowed[i+1:]
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 1 && strings.TrimSpace(string(t)) != "" {
+				return fmt.Errorf("text \"%v\" outside of an element", strings.TrimSpace(string(t)))
+			}
+		}
+	}
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
 const templateSkeleton string = `<?xml version="1.0" encoding="UTF-8"?>
-<doi_batch version="4.4.1" 
-           xmlns="http://www.crossref.org/schema/4.4.1"
+<doi_batch version="{{.SchemaVersion}}" 
+           xmlns="http://www.crossref.org/schema/{{.SchemaVersion}}"
+           xmlns:rel="http://www.crossref.org/relations.xsd"
+           xmlns:jats="http://www.ncbi.nlm.nih.gov/JATS1"
+           xmlns:mml="http://www.w3.org/1998/Math/MathML"
+           xmlns:ai="http://www.crossref.org/AccessIndicators.xsd"
+           xmlns:fr="http://www.crossref.org/fundref.xsd"
            xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" 
-           xsi:schemaLocation="http://www.crossref.org/schema/4.4.1 http://www.crossref.org/schemas/crossref4.4.1.xsd">
+           xsi:schemaLocation="http://www.crossref.org/schema/{{.SchemaVersion}} http://www.crossref.org/schemas/crossref{{.SchemaVersion}}.xsd">
 	<head>
         {{- with .HeadData}}
 		<doi_batch_id>{{.DOIBatch}}</doi_batch_id>
@@ -27,51 +128,165 @@ const templateSkeleton string = `<?xml version="1.0" encoding="UTF-8"?>
 				{{- range .ISSNs}}
 				<issn media_type="{{.Type}}">{{.Value}}</issn>
 				{{- end}}
+				{{- if .Coden}}
+				<coden>{{.Coden}}</coden>
+				{{- end}}
+				{{- if .ArchiveLocations}}
+				<archive_locations>
+					{{- range .ArchiveLocations}}
+					<archive name="{{.}}"/>
+					{{- end}}
+				</archive_locations>
+				{{- end}}
+				{{- if .JournalDOI}}
+				<doi_data>
+					<doi>{{.JournalDOI}}</doi>
+					<resource>{{.JournalURI}}</resource>
+				</doi_data>
+				{{- end}}
 			</journal_metadata>
 			<journal_issue>
+				{{- if .Contributors}}
+				{{- template "contributors" .Contributors}}
+				{{- end}}
+				{{- if .Title}}
+				<titles>
+					<title>{{.Title}}</title>
+				</titles>
+				{{- end}}
 				{{- range .PublicationDates}}
 				<publication_date media_type="{{.Type}}">
+					{{- if .Month}}
 					<month>{{.Month}}</month>
+					{{- end}}
+					{{- if .Day}}
 					<day>{{.Day}}</day>
+					{{- end}}
 					<year>{{.Year}}</year>
 				</publication_date>
 				{{- end}}
 				<journal_volume>
 					<volume>{{.Volume}}</volume>
+					{{- if .VolumeDOI}}
+					<doi_data>
+						<doi>{{.VolumeDOI}}</doi>
+						<resource>{{.VolumeURI}}</resource>
+					</doi_data>
+					{{- end}}
 				</journal_volume>
 				<issue>{{.Issue}}</issue>
+				{{- if .SpecialNumbering}}
+				<special_numbering>{{.SpecialNumbering}}</special_numbering>
+				{{- end}}
+				{{- if .IssueDOI}}
+				<doi_data>
+					<doi>{{.IssueDOI}}</doi>
+					<resource>{{.IssueURI}}</resource>
+				</doi_data>
+				{{- end}}
 			</journal_issue>
 			{{- range .Articles}}
-			<journal_article publication_type="full_text">
+			<journal_article publication_type="{{.PublicationType}}"{{if .Language}} language="{{.Language}}"{{end}}>
 				<titles>
 					<title>{{.Title}}</title>
+					{{- if .Subtitle}}
+					<subtitle>{{.Subtitle}}</subtitle>
+					{{- end}}
+					{{- with .OriginalLanguageTitle}}
+					<original_language_title{{if .Language}} language="{{.Language}}"{{end}}>{{.Title}}</original_language_title>
+					{{- if .Subtitle}}
+					<subtitle>{{.Subtitle}}</subtitle>
+					{{- end}}
+					{{- end}}
 				</titles>
-				<contributors>
-				{{- range .Contributors}}
-					<person_name sequence="{{.Sequence}}" contributor_role="{{.Role}}">
-{{- if .GivenName}}{{"\n"}}						<given_name>{{.GivenName}}</given_name>{{end}}
-						<surname>{{.Surname}}</surname>
-{{- if .Affiliation}}{{"\n"}}						<affiliation>{{.Affiliation}}</affiliation>{{end}}
-{{- if .ORCID}}{{"\n"}}						<ORCID>{{.ORCID}}</ORCID>{{end}}
-					</person_name>
+				{{- template "contributors" .Contributors}}
+				{{- if .Abstract}}
+				<jats:abstract>
+					{{.Abstract}}
+				</jats:abstract>
 				{{- end}}
-				</contributors>
 				{{- range .PublicationDates}}
 				<publication_date media_type="{{.Type}}">
+					{{- if .Month}}
 					<month>{{.Month}}</month>
+					{{- end}}
+					{{- if .Day}}
 					<day>{{.Day}}</day>
+					{{- end}}
 					<year>{{.Year}}</year>
 				</publication_date>
 				{{- end}}
+				{{- if .FirstPage}}
 				<pages>
 					<first_page>{{.FirstPage}}</first_page>
 					{{- if .LastPage}}
 					<last_page>{{.LastPage}}</last_page>{{end}}
+					{{- if .OtherPages}}
+					<other_pages>{{.OtherPages}}</other_pages>{{end}}
 				</pages>
+				{{- end}}
+				{{- if .ItemNumber}}
+				<publisher_item>
+					<item_number item_number_type="article_number">{{.ItemNumber}}</item_number>
+				</publisher_item>
+				{{- end}}
+				{{- with .Crossmark}}
+				<crossmark>
+					<crossmark_version>1</crossmark_version>
+					<crossmark_policy>{{.Policy}}</crossmark_policy>
+					{{- if .Updates}}
+					<updates>
+						{{- range .Updates}}
+						<update type="{{.Type}}" date="{{.Date}}">{{.DOI}}</update>
+						{{- end}}
+					</updates>
+					{{- end}}
+				</crossmark>
+				{{- end}}
+				{{- if .StaticXML}}
+				{{.StaticXML}}
+				{{- end}}
+				{{- if .Relations}}
+				<rel:program name="relations">
+					{{- range .Relations}}
+					<rel:related_item>
+						<rel:{{.Element}} relationship-type="{{.Type}}" identifier-type="{{.IdentifierType}}">{{.Identifier}}</rel:{{.Element}}>
+					</rel:related_item>
+					{{- end}}
+				</rel:program>
+				{{- end}}
+				{{- if .ArchiveLocations}}
+				<archive_locations>
+					{{- range .ArchiveLocations}}
+					<archive name="{{.}}"/>
+					{{- end}}
+				</archive_locations>
+				{{- end}}
 				<doi_data>
 					<doi>{{.DOI}}</doi>
 					<resource>{{.URI}}</resource>
+					{{- with .CrawlerLinks}}
+					<collection property="crawler-based">
+						{{- range .}}
+						<item crawler="iParadigms">
+							<resource>{{.URI}}</resource>
+						</item>
+						{{- end}}
+					</collection>
+					{{- end}}
+					{{- if .FullTextLinks}}
+					<collection property="text-mining">
+						{{- range .FullTextLinks}}
+						<item>
+							<resource{{if .MimeType}} mime_type="{{.MimeType}}"{{end}}>{{.URI}}</resource>
+						</item>
+						{{- end}}
+					</collection>
+					{{- end}}
 				</doi_data>
+				{{- if .Citations}}
+				{{- template "citation_list" .Citations}}
+				{{- end}}
 			</journal_article>
 			{{- end}}
 		</journal>
@@ -79,3 +294,85 @@ const templateSkeleton string = `<?xml version="1.0" encoding="UTF-8"?>
 	</body>
 </doi_batch>
 `
+
+const resourceTemplateSkeleton string = `<?xml version="1.0" encoding="UTF-8"?>
+<doi_batch version="4.4.1" 
+           xmlns="http://www.crossref.org/doi_resources_schema/4.4.1"
+           xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" 
+           xsi:schemaLocation="http://www.crossref.org/doi_resources_schema/4.4.1 http://www.crossref.org/schemas/doi_resources4.4.1.xsd">
+	<head>
+        {{- with .HeadData}}
+		<doi_batch_id>{{.DOIBatch}}</doi_batch_id>
+		<depositor>
+			<depositor_name>{{.DepositorName}}</depositor_name>
+			<email_address>{{.DepositorEmail}}</email_address>
+		</depositor>
+        {{- end}}
+	</head>
+	<body>
+		{{- range .Journals }}
+		{{- range .Articles}}
+		{{- if .Citations}}
+		<doi_citations>
+			<doi>{{.DOI}}</doi>
+			{{- template "citation_list" .Citations}}
+		</doi_citations>
+		{{- end}}
+		{{- end}}
+		{{- end}}
+	</body>
+</doi_batch>
+`
+
+const citationListTemplate string = `{{define "citation_list"}}
+				<citation_list>
+					{{- range .}}
+					<citation key="{{.Key}}">
+						{{- if .ISSN}}
+						<issn>{{.ISSN}}</issn>{{end}}
+						{{- if .JournalTitle}}
+						<journal_title>{{.JournalTitle}}</journal_title>{{end}}
+						{{- if .Author}}
+						<author>{{.Author}}</author>{{end}}
+						{{- if .Volume}}
+						<volume>{{.Volume}}</volume>{{end}}
+						{{- if .Issue}}
+						<issue>{{.Issue}}</issue>{{end}}
+						{{- if .FirstPage}}
+						<first_page>{{.FirstPage}}</first_page>{{end}}
+						{{- if .Year}}
+						<cYear>{{.Year}}</cYear>{{end}}
+						{{- if .DOI}}
+						<doi>{{.DOI}}</doi>{{end}}
+						{{- if .ArticleTitle}}
+						<article_title>{{.ArticleTitle}}</article_title>{{end}}
+						{{- if .Unstructured}}
+						<unstructured_citation>{{.Unstructured}}</unstructured_citation>{{end}}
+					</citation>
+					{{- end}}
+				</citation_list>
+{{- end}}`
+
+const contributorsTemplate string = `{{define "contributors"}}
+				<contributors>
+				{{- range .}}
+				{{- if .Organization}}
+					<organization sequence="{{.Sequence}}" contributor_role="{{.Role}}">{{.Organization}}</organization>
+				{{- else}}
+					<person_name sequence="{{.Sequence}}" contributor_role="{{.Role}}">
+{{- if .GivenName}}{{"\n"}}						<given_name>{{.GivenName}}</given_name>{{end}}
+						<surname>{{.Surname}}</surname>
+{{- if .Suffix}}{{"\n"}}						<suffix>{{.Suffix}}</suffix>{{end}}
+{{- if .Affiliations}}{{if institutions}}{{"\n"}}						<affiliations>
+{{- range .Affiliations}}{{"\n"}}							<institution>
+								<institution_name>{{.Name}}</institution_name>
+{{- if .ROR}}{{"\n"}}								<institution_id type="ror">{{.ROR}}</institution_id>{{end}}
+							</institution>
+{{- end}}{{"\n"}}						</affiliations>
+{{- else}}{{range .Affiliations}}{{"\n"}}						<affiliation>{{.Name}}</affiliation>{{end}}{{end}}{{end}}
+{{- if .ORCID}}{{"\n"}}						<ORCID authenticated="{{.ORCIDAuthenticated}}">{{.ORCID}}</ORCID>{{end}}
+					</person_name>
+				{{- end}}
+				{{- end}}
+				</contributors>
+{{- end}}`